@@ -2,22 +2,42 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/contract"
 	domainservice "github.com/mikiasgoitom/RevProx/internal/domain/service"
 	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
 	"github.com/mikiasgoitom/RevProx/internal/handler"
+	"github.com/mikiasgoitom/RevProx/internal/infrastructure/accesslog"
+	"github.com/mikiasgoitom/RevProx/internal/infrastructure/audit"
 	"github.com/mikiasgoitom/RevProx/internal/infrastructure/configservice"
+	"github.com/mikiasgoitom/RevProx/internal/infrastructure/httpserver"
 	"github.com/mikiasgoitom/RevProx/internal/infrastructure/logger"
 	metricsadapter "github.com/mikiasgoitom/RevProx/internal/infrastructure/metrics_adapter"
 	"github.com/mikiasgoitom/RevProx/internal/infrastructure/repository"
+	"github.com/mikiasgoitom/RevProx/internal/infrastructure/snapshot"
 	"github.com/mikiasgoitom/RevProx/internal/infrastructure/timeservice"
+	"github.com/mikiasgoitom/RevProx/internal/infrastructure/tracing"
+	"github.com/mikiasgoitom/RevProx/internal/infrastructure/warmup"
 	"github.com/mikiasgoitom/RevProx/internal/usecase"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take once a stop signal arrives.
+const shutdownTimeout = 10 * time.Second
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "snapshot" {
+		os.Exit(runSnapshotCommand(os.Args[2:]))
+	}
 
 	// ---------------infrastructure implementation---------------
 	cfgService := configservice.NewViperAdapter()
@@ -32,39 +52,266 @@ func main() {
 	}
 	appLogger.Info(context.Background(), "Configuration loaded successfully")
 	timeService := timeservice.NewTimeService()
-	originRepo, err := repository.NewHttpOriginRepository(cfg.Origin.OriginUrl, timeService)
+	tracer, shutdownTracing, err := tracing.NewOtelTracer(context.Background(), cfg.Tracing)
+	if err != nil {
+		appLogger.Error(context.Background(), "failed to initialize tracing", valueobject.LogField{Key: "error", Value: err})
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			appLogger.Error(context.Background(), "failed to flush traces", valueobject.LogField{Key: "error", Value: err})
+		}
+	}()
+	accessLogger, err := accesslog.NewAccessLogger(cfg.AccessLog)
+	if err != nil {
+		appLogger.Error(context.Background(), "failed to create access logger", valueobject.LogField{Key: "error", Value: err})
+		os.Exit(1)
+	}
+	defer accessLogger.Close()
+	windowStats := metricsadapter.NewWindowStatsAdapter(timeService)
+	prometheusMetrics := metricsadapter.NewMultiMetricsAdapter(metricsadapter.NewPrometheusAdapter(), windowStats)
+	originRepo, err := newOriginRepository(cfg.Origin, prometheusMetrics, timeService, appLogger)
 	if err != nil {
 		appLogger.Error(context.Background(), "failed to create origin repository", valueobject.LogField{Key: "error", Value: err})
 		os.Exit(1)
 	}
-	cacheRepo, err := repository.NewCacheRepository(cfg)
+	cacheRepo, err := repository.NewCacheRepository(cfg, prometheusMetrics, appLogger)
 	if err != nil {
 		appLogger.Error(context.Background(), "failed to create cache repository", valueobject.LogField{Key: "error", Value: err})
 		os.Exit(1)
 	}
-	prometheusMetrics := metricsadapter.NewPrometheusAdapter()
+	prometheusMetrics.ObserveCache(cacheRepo)
 	policyEvaluator := domainservice.NewPolicyEvaluator()
+	hotKeyDecay := time.Duration(cfg.HotKeys.DecaySeconds) * time.Second
+	hotKeys, err := domainservice.NewHotKeyTracker(cfg.HotKeys.Capacity, cfg.HotKeys.SketchWidth, cfg.HotKeys.SketchDepth, hotKeyDecay)
+	if err != nil {
+		appLogger.Error(context.Background(), "failed to create hot key tracker", valueobject.LogField{Key: "error", Value: err})
+		os.Exit(1)
+	}
+	hotClients, err := domainservice.NewHotKeyTracker(cfg.HotKeys.Capacity, cfg.HotKeys.SketchWidth, cfg.HotKeys.SketchDepth, hotKeyDecay)
+	if err != nil {
+		appLogger.Error(context.Background(), "failed to create hot key tracker", valueobject.LogField{Key: "error", Value: err})
+		os.Exit(1)
+	}
+	prometheusMetrics.ObserveHotKeys(hotKeys, hotClients, cfg.HotKeys.PrometheusTop)
+	cacheKeyBuilder := domainservice.NewCacheKeyBuilder(cfg.ToCacheKeyPolicyEntity())
+	rateLimiter := domainservice.NewTokenBucketLimiter()
+	prometheusMetrics.ObserveRateLimiter(rateLimiter)
 	// ---------------usecase implementaion---------------
 
-	proxyUsecase := usecase.NewProxyUsecase(timeService, cacheRepo, prometheusMetrics, appLogger, originRepo, policyEvaluator, cfg.Cache.ToCachePolicyEntity())
-	healthCheckUsecase := usecase.NewHealthCheckUseCase(appLogger, originRepo, cacheRepo)
+	proxyUsecase := usecase.NewProxyUsecase(timeService, cacheRepo, prometheusMetrics, appLogger, originRepo, policyEvaluator, cfg.Cache.ToCachePolicyEntity(), cacheKeyBuilder, tracer, hotKeys, hotClients)
+	warmupHeaders := http.Header{}
+	for name, value := range cfg.Warmup.Headers {
+		warmupHeaders.Set(name, value)
+	}
+	warmupUsecase := usecase.NewWarmupUseCase(proxyUsecase, warmupSources(cfg.Warmup, originRepo), timeService, appLogger, cfg.Warmup.Concurrency, cfg.Warmup.RatePerSecond, cfg.Warmup.MaxURLs, warmupHeaders)
+	var readinessGates []contract.IReadinessGate
+	if cfg.Warmup.OnStartup && cfg.Warmup.WaitForReadiness {
+		readinessGates = append(readinessGates, warmupUsecase)
+	}
+	healthCheckUsecase := usecase.NewHealthCheckUseCase(appLogger, originRepo, cacheRepo, readinessGates...)
+	explainUsecase := usecase.NewExplainUseCase(timeService, cacheRepo, originRepo, policyEvaluator, cfg.Cache.ToCachePolicyEntity(), cacheKeyBuilder, appLogger)
+	statsUsecase := usecase.NewStatsUseCase(cacheRepo, windowStats, timeService, hotKeys, hotClients)
+	inspectUsecase := usecase.NewCacheInspectUseCase(cacheRepo)
+	purgeUsecase := usecase.NewPurgeUseCase(cacheRepo, appLogger)
+	snapshotUsecase := usecase.NewSnapshotUseCase(cacheRepo, snapshot.NewGzipArchive(), timeService, appLogger)
+	if cfg.Cache.Snapshot.Path != "" {
+		loadSnapshot(snapshotUsecase, cfg.Cache.Snapshot.Path, appLogger)
+	}
 
 	// --------------- handler implementation---------------
 	healthCheckHandler := handler.NewHealthCheckHandler(healthCheckUsecase, appLogger)
 	prometheusHandler := handler.NewPrometheusHandler()
-	proxyHandler := handler.NewProxyHandler(proxyUsecase, appLogger)
+	proxyHandler := handler.NewProxyHandler(proxyUsecase, appLogger, handler.NewServerTiming(cfg.ServerTiming))
+	adminHandler := handler.NewAdminHandler(statsUsecase, explainUsecase, inspectUsecase, purgeUsecase, cfg, appLogger)
+	snapshotHandler := handler.NewSnapshotHandler(snapshotUsecase, timeService, appLogger)
+	warmupHandler := handler.NewWarmupHandler(warmupUsecase)
+	metricsMiddleware := handler.NewMetricsMiddleware(prometheusMetrics, timeService, appLogger)
+	tracingMiddleware := handler.NewTracingMiddleware(tracer)
+	requestIDMiddleware := handler.NewRequestIDMiddleware()
+	accessLogMiddleware := handler.NewAccessLogMiddleware(accessLogger, timeService)
+	auditLogger := audit.NewAuditLogger(cfg.Admin.AuditLogPath)
+	defer auditLogger.Close()
+	adminAuthMiddleware, err := handler.NewAdminAuthMiddleware(cfg.Admin, auditLogger, timeService)
+	if err != nil {
+		appLogger.Error(context.Background(), "failed to configure admin authentication", valueobject.LogField{Key: "error", Value: err})
+		os.Exit(1)
+	}
 
 	// --------------- router setup---------------
-	router := handler.NewRouter(healthCheckHandler, prometheusHandler, proxyHandler)
+	rateLimitMiddleware, err := handler.NewRateLimitMiddleware(cfg.RateLimit, rateLimiter, prometheusMetrics, timeService, appLogger)
+	if err != nil {
+		appLogger.Error(context.Background(), "failed to configure rate limiting", valueobject.LogField{Key: "error", Value: err})
+		os.Exit(1)
+	}
+	router := handler.NewRouter(healthCheckHandler, prometheusHandler, proxyHandler, adminHandler, snapshotHandler, warmupHandler, metricsMiddleware, tracingMiddleware, requestIDMiddleware, accessLogMiddleware, adminAuthMiddleware, rateLimitMiddleware)
 
-	ginEngine := gin.Default()
+	// gin's console logger is replaced by the access log middleware
+	ginEngine := gin.New()
+	ginEngine.Use(gin.Recovery())
+	// gin trusts forwarding headers from any peer unless told otherwise
+	if err := ginEngine.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		appLogger.Error(context.Background(), "invalid server.trusted_proxies", valueobject.LogField{Key: "error", Value: err})
+		os.Exit(1)
+	}
 
 	router.SetupRoutes(ginEngine)
 
+	if len(cfg.Admin.Tokens) == 0 && cfg.Admin.ClientCAFile == "" && len(cfg.Admin.AllowedCIDRs) == 0 {
+		appLogger.Warn(context.Background(), "admin API is not protected: configure admin.tokens, admin.client_ca_file or admin.allowed_cidrs")
+	}
+	// config validation ensures a client CA is only set when the admin listener enforces mTLS
+	var adminServer *http.Server
+	if cfg.Admin.Listen == "" {
+		router.SetupAdminRoutes(ginEngine, false)
+	} else {
+		adminEngine := gin.New()
+		adminEngine.Use(gin.Recovery())
+		if err := adminEngine.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+			appLogger.Error(context.Background(), "invalid server.trusted_proxies", valueobject.LogField{Key: "error", Value: err})
+			os.Exit(1)
+		}
+		router.SetupAdminRoutes(adminEngine, true)
+		adminListener, err := httpserver.NewAdminListener(cfg.Admin)
+		if err != nil {
+			appLogger.Error(context.Background(), "failed to open admin listener", valueobject.LogField{Key: "error", Value: err})
+			os.Exit(1)
+		}
+		appLogger.Info(context.Background(), "Starting admin server on "+cfg.Admin.Listen)
+		// snapshot transfers stream for as long as they need, so only the header and idle
+		// timeouts apply to the admin listener
+		adminServer = newHTTPServer(cfg.Server, adminEngine)
+		adminServer.ReadTimeout, adminServer.WriteTimeout = 0, 0
+		go func() {
+			if err := adminServer.Serve(adminListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				appLogger.Error(context.Background(), "admin server stopped", valueobject.LogField{Key: "error", Value: err})
+			}
+		}()
+	}
+
 	// --------------- start server---------------
-	appLogger.Info(context.Background(), "Starting server on port "+cfg.Server.Port)
-	if err := ginEngine.Run(":" + cfg.Server.Port); err != nil {
-		appLogger.Error(context.Background(), "failed to start server", valueobject.LogField{Key: "error", Value: err})
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := newHTTPServer(cfg.Server, ginEngine)
+	server.Addr = ":" + cfg.Server.Port
+	go func() {
+		appLogger.Info(context.Background(), "Starting server on port "+cfg.Server.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			appLogger.Error(context.Background(), "failed to start server", valueobject.LogField{Key: "error", Value: err})
+			stop()
+		}
+	}()
+	if cfg.Warmup.OnStartup {
+		warmupUsecase.Start(ctx, "startup", nil)
+	}
+	if cfg.Warmup.IntervalSeconds > 0 {
+		go warmupUsecase.Schedule(ctx, time.Duration(cfg.Warmup.IntervalSeconds)*time.Second)
 	}
+	<-ctx.Done()
 
+	appLogger.Info(context.Background(), "Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error(context.Background(), "server shutdown did not complete", valueobject.LogField{Key: "error", Value: err})
+	}
+	if adminServer != nil {
+		if err := adminServer.Shutdown(shutdownCtx); err != nil {
+			appLogger.Error(context.Background(), "admin server shutdown did not complete", valueobject.LogField{Key: "error", Value: err})
+		}
+	}
+	if cfg.Cache.Snapshot.Path != "" {
+		saveSnapshot(snapshotUsecase, cfg.Cache.Snapshot.Path, appLogger)
+	}
+}
+
+// newHTTPServer applies the configured inbound timeouts and header limit.
+func newHTTPServer(cfg config.ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(cfg.ReadHeaderTimeoutSeconds) * time.Second,
+		ReadTimeout:       time.Duration(cfg.ReadTimeoutSeconds) * time.Second,
+		WriteTimeout:      time.Duration(cfg.WriteTimeoutSeconds) * time.Second,
+		IdleTimeout:       time.Duration(cfg.IdleTimeoutSeconds) * time.Second,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// newOriginRepository builds a repository per upstream, each behind its own concurrency
+// limit when one is configured, and adds retries and hedging across them.
+func newOriginRepository(cfg config.OriginConfig, metrics contract.IMetricsAdapter, timeService contract.ITimeService, logger contract.ILogger) (contract.IOriginRepository, error) {
+	var upstreams []contract.IOriginRepository
+	for i, originURL := range append([]string{cfg.OriginUrl}, cfg.Upstreams...) {
+		upstream, err := repository.NewHttpOriginRepository(originURL, cfg.Timeouts.ToOriginTimeouts(), cfg.Transport.ToOriginTransport(), metrics, timeService, logger)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Concurrency.MaxInFlight > 0 {
+			limiter := domainservice.NewConcurrencyLimiter(cfg.Concurrency.ToConcurrencyLimit())
+			// label by position so that upstreams on the same host stay apart and no
+			// credentials or query strings from the URL end up in metrics
+			metrics.ObserveOriginLimiter(strconv.Itoa(i), limiter)
+			upstream = repository.NewLimitedOriginRepository(upstream, limiter, metrics, timeService, logger)
+		}
+		upstreams = append(upstreams, upstream)
+	}
+
+	policy := cfg.Retry.ToRetryPolicy()
+	if len(upstreams) == 1 && policy.MaxAttempts <= 1 {
+		return upstreams[0], nil
+	}
+	budget := domainservice.NewRetryBudget(cfg.Retry.BudgetRatio, cfg.Retry.BudgetMinPerSecond)
+	return repository.NewRetryingOriginRepository(upstreams, policy, budget, metrics, timeService, logger), nil
+}
+
+// warmupSources returns the configured seed URL sources for cache warming.
+func warmupSources(cfg config.WarmupConfig, originRepo contract.IOriginRepository) []contract.IWarmupSource {
+	var sources []contract.IWarmupSource
+	if cfg.URLFile != "" {
+		sources = append(sources, warmup.NewFileSource(cfg.URLFile))
+	}
+	if cfg.Sitemap != "" {
+		sources = append(sources, warmup.NewSitemapSource(originRepo, cfg.Sitemap))
+	}
+	if cfg.AccessLog != "" {
+		sources = append(sources, warmup.NewAccessLogSource(cfg.AccessLog))
+	}
+	return sources
+}
+
+// loadSnapshot restores the cache saved by a previous run, if there is one.
+func loadSnapshot(snapshotUC contract.ISnapshotUseCase, path string, appLogger contract.ILogger) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		appLogger.Error(context.Background(), "failed to open cache snapshot", valueobject.LogField{Key: "error", Value: err})
+		return
+	}
+	defer file.Close()
+	// failures are logged by the use case; a partially loaded cache is still usable
+	_, _ = snapshotUC.Import(context.Background(), file)
+}
+
+// saveSnapshot writes the cache next to path and renames it into place, so a crash while
+// writing never leaves a truncated archive behind.
+func saveSnapshot(snapshotUC contract.ISnapshotUseCase, path string, appLogger contract.ILogger) {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		appLogger.Error(context.Background(), "failed to create cache snapshot", valueobject.LogField{Key: "error", Value: err})
+		return
+	}
+	_, err = snapshotUC.Export(context.Background(), file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		appLogger.Error(context.Background(), "failed to save cache snapshot", valueobject.LogField{Key: "error", Value: err})
+	}
 }
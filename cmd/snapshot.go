@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/infrastructure/configservice"
+)
+
+const snapshotUsage = `usage: revprox snapshot export [flags] [-o file]
+       revprox snapshot import [flags] [-i file]
+
+Downloads the cache of a running instance as a snapshot archive, or loads an archive into
+it, through the admin API. The admin address and TLS mode are read from the configuration.
+
+flags:
+`
+
+// runSnapshotCommand implements "revprox snapshot export|import" and returns the exit code.
+func runSnapshotCommand(args []string) int {
+	if len(args) == 0 || (args[0] != "export" && args[0] != "import") {
+		fmt.Fprint(os.Stderr, snapshotUsage)
+		return 2
+	}
+	action := args[0]
+	flags := flag.NewFlagSet("snapshot "+action, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, snapshotUsage)
+		flags.PrintDefaults()
+	}
+	file := flags.String("o", "-", "archive to write, - for stdout (export)")
+	flags.StringVar(file, "i", "-", "archive to read, - for stdin (import)")
+	adminURL := flags.String("admin", "", "admin API base URL, e.g. https://10.0.0.5:9091 (default from admin.listen)")
+	token := flags.String("token", os.Getenv("REVPROX_ADMIN_TOKEN"), "admin bearer token (default $REVPROX_ADMIN_TOKEN)")
+	caFile := flags.String("ca", "", "CA bundle to verify the admin certificate")
+	certFile := flags.String("cert", "", "client certificate for mTLS")
+	keyFile := flags.String("key", "", "client key for mTLS")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := configservice.NewViperAdapter().Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		return 1
+	}
+	client, baseURL, err := adminClient(cfg.Admin, *adminURL, *caFile, *certFile, *keyFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if action == "export" {
+		err = exportSnapshot(client, baseURL, *token, *file)
+	} else {
+		err = importSnapshot(client, baseURL, *token, *file)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// adminClient builds an HTTP client for the admin listener, dialing the unix socket when
+// admin.listen names one.
+func adminClient(cfg config.AdminConfig, override, caFile, certFile, keyFile string) (*http.Client, string, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	scheme := "http"
+	if cfg.CertFile != "" || caFile != "" || certFile != "" {
+		scheme = "https"
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if caFile != "" {
+			pem, err := os.ReadFile(caFile)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read CA bundle: %w", err)
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, "", fmt.Errorf("no certificates found in %s", caFile)
+			}
+		}
+		if certFile != "" {
+			cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to load client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	client := &http.Client{Transport: transport}
+
+	if override != "" {
+		return client, strings.TrimSuffix(override, "/"), nil
+	}
+	if cfg.Listen == "" {
+		return nil, "", errors.New("admin.listen is empty; pass -admin with the proxy's base URL")
+	}
+	if socket, ok := strings.CutPrefix(cfg.Listen, "unix:"); ok {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+		return client, scheme + "://unix", nil
+	}
+	return client, scheme + "://" + cfg.Listen, nil
+}
+
+func exportSnapshot(client *http.Client, baseURL, token, file string) error {
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/api/v1/admin/snapshot", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := sendAdminRequest(client, req, token)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	out := os.Stdout
+	if file != "-" {
+		if out, err = os.Create(file); err != nil {
+			return err
+		}
+	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to download snapshot: %w", err)
+	}
+	return out.Close()
+}
+
+func importSnapshot(client *http.Client, baseURL, token, file string) error {
+	in := os.Stdin
+	if file != "-" {
+		var err error
+		if in, err = os.Open(file); err != nil {
+			return err
+		}
+		defer in.Close()
+	}
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/api/v1/admin/snapshot", in)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	resp, err := sendAdminRequest(client, req, token)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(os.Stdout, resp.Body)
+	return err
+}
+
+// sendAdminRequest adds the bearer token and turns non-2xx answers into errors.
+func sendAdminRequest(client *http.Client, req *http.Request, token string) (*http.Response, error) {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("admin API request failed: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("admin API answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
@@ -8,37 +10,363 @@ import (
 )
 
 type Config struct {
-	Server ServerConfig
-	Cache  CacheConfig
-	Origin OriginConfig
+	Server    ServerConfig
+	Cache     CacheConfig
+	Origin    OriginConfig
+	Tracing   TracingConfig
+	AccessLog AccessLogConfig `mapstructure:"access_log"`
+	HotKeys   HotKeysConfig   `mapstructure:"hot_keys"`
+	// ServerTiming controls the Server-Timing header on proxied responses.
+	ServerTiming ServerTimingConfig `mapstructure:"server_timing"`
+	Admin        AdminConfig        `mapstructure:"admin"`
+	Warmup       WarmupConfig       `mapstructure:"warmup"`
+	RateLimit    RateLimitConfig    `mapstructure:"rate_limit"`
 }
 
 type ServerConfig struct {
 	Port       string `mapstructure:"port"`
 	Production bool   `mapstructure:"production"`
+	// Timeouts of the inbound listener; zero disables one. The write timeout must exceed the
+	// longest origin fetch, including retries, backoff and queueing, or loading fails.
+	ReadHeaderTimeoutSeconds int64 `mapstructure:"read_header_timeout_seconds"`
+	ReadTimeoutSeconds       int64 `mapstructure:"read_timeout_seconds"`
+	WriteTimeoutSeconds      int64 `mapstructure:"write_timeout_seconds"`
+	IdleTimeoutSeconds       int64 `mapstructure:"idle_timeout_seconds"`
+	MaxHeaderBytes           int   `mapstructure:"max_header_bytes"`
+	// TrustedProxies lists the addresses or CIDRs of reverse proxies whose X-Forwarded-For
+	// and X-Real-IP headers are believed. By default none are and the client IP is the peer
+	// address.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 type CacheConfig struct {
-	MaxCost     string       `mapstructure:"max_cost"`
-	NumCounters int64        `mapstructure:"num_counters"`
-	BufferItems int64        `mapstructure:"buffer_items"`
-	Policy      PolicyConfig `mapstructure:"policy"`
+	MaxCost     string         `mapstructure:"max_cost"`
+	NumCounters int64          `mapstructure:"num_counters"`
+	BufferItems int64          `mapstructure:"buffer_items"`
+	Policy      PolicyConfig   `mapstructure:"policy"`
+	Key         KeyConfig      `mapstructure:"key"`
+	Snapshot    SnapshotConfig `mapstructure:"snapshot"`
 }
+type SnapshotConfig struct {
+	// Path is loaded into the cache at startup when it exists and rewritten on shutdown, so
+	// the in-memory cache survives restarts. Empty disables both.
+	Path string `mapstructure:"path"`
+}
+
 type OriginConfig struct {
-	OriginUrl string `mapstructure:"origin_url"`
+	OriginUrl string `mapstructure:"origin_url" redact:"url"`
+	// Name identifies the origin in cache keys; defaults to the origin host.
+	Name string `mapstructure:"name"`
+	// Upstreams are alternative base URLs serving the same content as OriginUrl. Retries and
+	// hedged requests go to them in turn; each gets its own concurrency limit.
+	Upstreams   []string                `mapstructure:"upstreams" redact:"url"`
+	Concurrency OriginConcurrencyConfig `mapstructure:"concurrency"`
+	Retry       OriginRetryConfig       `mapstructure:"retry"`
+	Timeouts    OriginTimeoutsConfig    `mapstructure:"timeouts"`
+	Transport   OriginTransportConfig   `mapstructure:"transport"`
+}
+
+type OriginTransportConfig struct {
+	MaxIdleConns        int `mapstructure:"max_idle_conns"`
+	MaxIdleConnsPerHost int `mapstructure:"max_idle_conns_per_host"`
+	// MaxConnsPerHost caps all connections to one upstream; zero is unlimited.
+	MaxConnsPerHost        int   `mapstructure:"max_conns_per_host"`
+	IdleConnTimeoutSeconds int64 `mapstructure:"idle_conn_timeout_seconds"`
+	// HTTP2 negotiates HTTP/2 with TLS origins; H2C uses cleartext HTTP/2 for http:// origins.
+	HTTP2             bool `mapstructure:"http2"`
+	H2C               bool `mapstructure:"h2c"`
+	DisableKeepAlives bool `mapstructure:"disable_keep_alives"`
+}
+
+type OriginTimeoutsConfig struct {
+	DialMs           int64 `mapstructure:"dial_ms"`
+	TLSHandshakeMs   int64 `mapstructure:"tls_handshake_ms"`
+	ResponseHeaderMs int64 `mapstructure:"response_header_ms"`
+	// TotalMs bounds one origin attempt including the body; a timed out request is answered
+	// with 504.
+	TotalMs       int64 `mapstructure:"total_ms"`
+	HealthCheckMs int64 `mapstructure:"health_check_ms"`
+	// Routes override TotalMs for proxied paths starting with PathPrefix; the longest prefix wins.
+	Routes []RouteTimeoutConfig `mapstructure:"routes"`
+}
+
+type RouteTimeoutConfig struct {
+	PathPrefix string `mapstructure:"path_prefix"`
+	TotalMs    int64  `mapstructure:"total_ms"`
+}
+
+type OriginRetryConfig struct {
+	// MaxAttempts includes the first try; one disables retries. Only idempotent requests
+	// that failed to connect, timed out or got one of Statuses are retried.
+	MaxAttempts int   `mapstructure:"max_attempts"`
+	BaseDelayMs int64 `mapstructure:"base_delay_ms"`
+	MaxDelayMs  int64 `mapstructure:"max_delay_ms"`
+	Statuses    []int `mapstructure:"statuses"`
+	// BudgetRatio is the share of requests that may be retried on top of BudgetMinPerSecond,
+	// e.g. 0.1 lets retries add at most 10% to the origin load.
+	BudgetRatio        float64 `mapstructure:"budget_ratio"`
+	BudgetMinPerSecond float64 `mapstructure:"budget_min_per_second"`
+	// HedgeAfterMs sends a second attempt to the next upstream when the first has not
+	// answered in time; zero disables hedging.
+	HedgeAfterMs int64 `mapstructure:"hedge_after_ms"`
+}
+
+type OriginConcurrencyConfig struct {
+	// MaxInFlight caps concurrent origin requests; zero leaves them unlimited.
+	MaxInFlight int `mapstructure:"max_in_flight"`
+	// MaxQueue requests wait for a slot for at most QueueTimeoutMs; beyond that they fail
+	// fast, or get a stale copy with cache.policy.stale_if_overloaded.
+	MaxQueue       int   `mapstructure:"max_queue"`
+	QueueTimeoutMs int64 `mapstructure:"queue_timeout_ms"`
+	// Adaptive lowers the limit towards MinInFlight while origin requests fail or take longer
+	// than LatencyThresholdMs, and raises it back towards MaxInFlight once they recover.
+	Adaptive           bool  `mapstructure:"adaptive"`
+	MinInFlight        int   `mapstructure:"min_in_flight"`
+	LatencyThresholdMs int64 `mapstructure:"latency_threshold_ms"`
+}
+
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Exporter is "otlp" (OTLP over HTTP) or "stdout".
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	ServiceName string  `mapstructure:"service_name"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+type AccessLogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Format is "common", "combined", "json", "logfmt" or "custom".
+	Format string `mapstructure:"format"`
+	// Template is a text/template over entity.AccessLogEntry used by the custom format.
+	Template string `mapstructure:"template"`
+	// Extended appends cache status, upstream address and upstream time to common and
+	// combined lines.
+	Extended bool `mapstructure:"extended"`
+	// Path is the log file; empty or "stdout" writes to standard output without rotation.
+	Path                  string `mapstructure:"path"`
+	MaxSizeMB             int    `mapstructure:"max_size_mb"`
+	MaxBackups            int    `mapstructure:"max_backups"`
+	MaxAgeDays            int    `mapstructure:"max_age_days"`
+	Compress              bool   `mapstructure:"compress"`
+	RotateIntervalSeconds int64  `mapstructure:"rotate_interval_seconds"`
+}
+
+type HotKeysConfig struct {
+	// Capacity is how many keys and clients are ranked per dimension.
+	Capacity     int   `mapstructure:"capacity"`
+	SketchWidth  int   `mapstructure:"sketch_width"`
+	SketchDepth  int   `mapstructure:"sketch_depth"`
+	DecaySeconds int64 `mapstructure:"decay_seconds"`
+	// PrometheusTop bounds how many ranked entries are exported as metric labels.
+	PrometheusTop int `mapstructure:"prometheus_top"`
+}
+
+type AdminConfig struct {
+	// Listen is the admin listener address, e.g. "127.0.0.1:9091" or "unix:/run/revprox.sock".
+	// Empty serves the admin and metrics routes on the proxy port.
+	Listen string `mapstructure:"listen"`
+	// Tokens maps caller names to bearer tokens; the name is recorded in the audit log.
+	Tokens map[string]string `mapstructure:"tokens" redact:"true"`
+	// AllowedCIDRs restricts TCP callers to these networks.
+	AllowedCIDRs []string `mapstructure:"allowed_cidrs"`
+	// CertFile and KeyFile serve the admin listener over TLS; ClientCAFile additionally
+	// requires client certificates signed by that CA (mTLS).
+	CertFile     string `mapstructure:"cert_file"`
+	KeyFile      string `mapstructure:"key_file"`
+	ClientCAFile string `mapstructure:"client_ca_file"`
+	// AuditLogPath receives one JSON line per admin action; empty writes to stdout.
+	AuditLogPath string `mapstructure:"audit_log_path"`
+}
+
+type WarmupConfig struct {
+	// URLFile lists seed URLs, one absolute URL or path per line.
+	URLFile string `mapstructure:"url_file"`
+	// Sitemap is the path of a sitemap.xml on the origin, e.g. "/sitemap.xml".
+	Sitemap string `mapstructure:"sitemap"`
+	// AccessLog is a previous access log of this proxy; its most requested URLs go first.
+	AccessLog string `mapstructure:"access_log"`
+	// OnStartup warms the cache when the proxy starts; IntervalSeconds repeats warming on a
+	// schedule when positive.
+	OnStartup       bool  `mapstructure:"on_startup"`
+	IntervalSeconds int64 `mapstructure:"interval_seconds"`
+	// WaitForReadiness reports not ready until the startup run has finished.
+	WaitForReadiness bool `mapstructure:"wait_for_readiness"`
+	Concurrency      int  `mapstructure:"concurrency"`
+	// RatePerSecond caps origin fetches per second; zero is unlimited.
+	RatePerSecond float64 `mapstructure:"rate_per_second"`
+	// MaxURLs caps a run; zero is unlimited.
+	MaxURLs int `mapstructure:"max_urls"`
+	// Headers are sent with every warm-up request, e.g. to warm an Accept-Encoding variant.
+	Headers map[string]string `mapstructure:"headers" redact:"true"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// APIKeyHeader carries the client's API key for rules keyed on "api_key".
+	APIKeyHeader string `mapstructure:"api_key_header"`
+	// APIKeys maps client names to the API keys they send; requests with an unknown key are
+	// keyed on their IP so that made-up keys cannot mint fresh buckets.
+	APIKeys map[string]string `mapstructure:"api_keys" redact:"true"`
+	// Default applies to proxied paths no route rule matches; a zero limit leaves them unlimited.
+	Default RateLimitRule `mapstructure:"default"`
+	// Routes override Default for proxied paths starting with PathPrefix; the longest prefix wins.
+	Routes []RateLimitRule `mapstructure:"routes"`
+}
+
+type RateLimitRule struct {
+	PathPrefix string `mapstructure:"path_prefix"`
+	// Key is "ip", "api_key" or "route" (one bucket shared by all clients).
+	Key string `mapstructure:"key"`
+	// Limit requests are allowed per WindowSeconds on average, in bursts of up to Burst
+	// requests; Burst defaults to Limit.
+	Limit         int   `mapstructure:"limit"`
+	WindowSeconds int64 `mapstructure:"window_seconds"`
+	Burst         int   `mapstructure:"burst"`
+}
+
+type ServerTimingConfig struct {
+	// Enabled adds the header to every proxied response.
+	Enabled bool `mapstructure:"enabled"`
+	// PathPrefixes adds the header to proxied paths starting with any of the prefixes.
+	PathPrefixes []string `mapstructure:"path_prefixes"`
+	// TriggerHeader adds the header when the request carries it with exactly TriggerValue,
+	// which is required, so only trusted clients can ask for timings.
+	TriggerHeader string `mapstructure:"trigger_header"`
+	TriggerValue  string `mapstructure:"trigger_value" redact:"true"`
 }
 
 type PolicyConfig struct {
-	DefaultTTLSeconds       int64 `mapstructure:"default_ttl_seconds"`
-	RespectNoCache          bool  `mapstructure:"respect_no_cache"`
-	RespectNoStore          bool  `mapstructure:"respect_no_store"`
-	RevalidateWindowSeconds int64 `mapstructure:"revalidate_window_seconds"`
+	DefaultTTLSeconds       int64    `mapstructure:"default_ttl_seconds"`
+	RespectNoCache          bool     `mapstructure:"respect_no_cache"`
+	RespectNoStore          bool     `mapstructure:"respect_no_store"`
+	RevalidateWindowSeconds int64    `mapstructure:"revalidate_window_seconds"`
+	HeuristicFraction       float64  `mapstructure:"heuristic_fraction"`
+	HeuristicMaxTTLSeconds  int64    `mapstructure:"heuristic_max_ttl_seconds"`
+	AdaptiveTTL             bool     `mapstructure:"adaptive_ttl"`
+	MinTTLSeconds           int64    `mapstructure:"min_ttl_seconds"`
+	MaxTTLSeconds           int64    `mapstructure:"max_ttl_seconds"`
+	SetCookieMode           string   `mapstructure:"set_cookie_mode"`
+	BypassCookies           []string `mapstructure:"bypass_cookies"`
+	// StatusTTLSeconds maps status codes to negative caching lifetimes, e.g. {"404": 30, "503": 5}.
+	StatusTTLSeconds     map[string]int64 `mapstructure:"status_ttl_seconds"`
+	MaxRetryAfterSeconds int64            `mapstructure:"max_retry_after_seconds"`
+	// BackoffOn429 backs off on a 429 with Retry-After as well as a 503; turn it off when the
+	// origin rate limits per client. On by default.
+	BackoffOn429 bool `mapstructure:"backoff_on_429"`
+	// StaleIfOverloaded serves a stale copy, when one is cached, instead of failing requests
+	// shed by the origin concurrency limit.
+	StaleIfOverloaded bool `mapstructure:"stale_if_overloaded"`
+}
+
+type KeyConfig struct {
+	QueryAllowlist      []string `mapstructure:"query_allowlist"`
+	QueryDenylist       []string `mapstructure:"query_denylist"`
+	Headers             []string `mapstructure:"headers"`
+	Cookies             []string `mapstructure:"cookies"`
+	IgnoreCase          bool     `mapstructure:"ignore_case"`
+	IgnoreTrailingSlash bool     `mapstructure:"ignore_trailing_slash"`
+	IncludeOrigin       bool     `mapstructure:"include_origin"`
 }
 
 func (pc *CacheConfig) ToCachePolicyEntity() entity.CachePolicy {
 	return entity.CachePolicy{
-		DefaultTTL:       valueobject.TTL{Duration: time.Duration(pc.Policy.DefaultTTLSeconds) * time.Second},
-		RespectNoCache:   pc.Policy.RespectNoCache,
-		RespectNoStore:   pc.Policy.RespectNoStore,
-		RevalidateWindow: time.Duration(pc.Policy.RevalidateWindowSeconds) * time.Second,
+		DefaultTTL: valueobject.TTL{
+			Duration: time.Duration(pc.Policy.DefaultTTLSeconds) * time.Second,
+			Adaptive: pc.Policy.AdaptiveTTL,
+			Min:      time.Duration(pc.Policy.MinTTLSeconds) * time.Second,
+			Max:      time.Duration(pc.Policy.MaxTTLSeconds) * time.Second,
+		},
+		RespectNoCache:    pc.Policy.RespectNoCache,
+		RespectNoStore:    pc.Policy.RespectNoStore,
+		RevalidateWindow:  time.Duration(pc.Policy.RevalidateWindowSeconds) * time.Second,
+		HeuristicFraction: pc.Policy.HeuristicFraction,
+		HeuristicMaxTTL:   time.Duration(pc.Policy.HeuristicMaxTTLSeconds) * time.Second,
+		SetCookieMode:     valueobject.SetCookieMode(pc.Policy.SetCookieMode),
+		BypassCookies:     pc.Policy.BypassCookies,
+		StatusTTLs:        pc.statusTTLs(),
+		MaxRetryAfter:     time.Duration(pc.Policy.MaxRetryAfterSeconds) * time.Second,
+		BackoffOn429:      pc.Policy.BackoffOn429,
+		StaleIfOverloaded: pc.Policy.StaleIfOverloaded,
+	}
+}
+
+func (pc *CacheConfig) statusTTLs() map[int]time.Duration {
+	ttls := make(map[int]time.Duration, len(pc.Policy.StatusTTLSeconds))
+	for code, seconds := range pc.Policy.StatusTTLSeconds {
+		// Validate has rejected keys that are not status codes
+		status, err := strconv.Atoi(code)
+		if err != nil {
+			continue
+		}
+		ttls[status] = time.Duration(seconds) * time.Second
+	}
+	return ttls
+}
+
+func (oc *OriginConcurrencyConfig) ToConcurrencyLimit() valueobject.ConcurrencyLimit {
+	return valueobject.ConcurrencyLimit{
+		Max:              oc.MaxInFlight,
+		MaxQueue:         oc.MaxQueue,
+		QueueTimeout:     time.Duration(oc.QueueTimeoutMs) * time.Millisecond,
+		Adaptive:         oc.Adaptive,
+		Min:              oc.MinInFlight,
+		LatencyThreshold: time.Duration(oc.LatencyThresholdMs) * time.Millisecond,
+	}
+}
+
+func (tc *OriginTimeoutsConfig) ToOriginTimeouts() valueobject.OriginTimeouts {
+	routes := make([]valueobject.RouteTimeout, 0, len(tc.Routes))
+	for _, route := range tc.Routes {
+		routes = append(routes, valueobject.RouteTimeout{PathPrefix: route.PathPrefix, Total: time.Duration(route.TotalMs) * time.Millisecond})
+	}
+	return valueobject.OriginTimeouts{
+		Dial:           time.Duration(tc.DialMs) * time.Millisecond,
+		TLSHandshake:   time.Duration(tc.TLSHandshakeMs) * time.Millisecond,
+		ResponseHeader: time.Duration(tc.ResponseHeaderMs) * time.Millisecond,
+		Total:          time.Duration(tc.TotalMs) * time.Millisecond,
+		HealthCheck:    time.Duration(tc.HealthCheckMs) * time.Millisecond,
+		Routes:         routes,
+	}
+}
+
+func (tc *OriginTransportConfig) ToOriginTransport() valueobject.OriginTransport {
+	return valueobject.OriginTransport{
+		MaxIdleConns:        tc.MaxIdleConns,
+		MaxIdleConnsPerHost: tc.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     tc.MaxConnsPerHost,
+		IdleConnTimeout:     time.Duration(tc.IdleConnTimeoutSeconds) * time.Second,
+		HTTP2:               tc.HTTP2,
+		H2C:                 tc.H2C,
+		DisableKeepAlives:   tc.DisableKeepAlives,
+	}
+}
+
+func (rc *OriginRetryConfig) ToRetryPolicy() valueobject.RetryPolicy {
+	return valueobject.RetryPolicy{
+		MaxAttempts: rc.MaxAttempts,
+		BaseDelay:   time.Duration(rc.BaseDelayMs) * time.Millisecond,
+		MaxDelay:    time.Duration(rc.MaxDelayMs) * time.Millisecond,
+		Statuses:    rc.Statuses,
+		HedgeAfter:  time.Duration(rc.HedgeAfterMs) * time.Millisecond,
+	}
+}
+
+func (c *Config) ToCacheKeyPolicyEntity() entity.CacheKeyPolicy {
+	originName := c.Origin.Name
+	if originName == "" {
+		if u, err := url.Parse(c.Origin.OriginUrl); err == nil {
+			originName = u.Host
+		}
+	}
+	return entity.CacheKeyPolicy{
+		QueryAllowlist:      c.Cache.Key.QueryAllowlist,
+		QueryDenylist:       c.Cache.Key.QueryDenylist,
+		Headers:             c.Cache.Key.Headers,
+		Cookies:             c.Cache.Key.Cookies,
+		IgnoreCase:          c.Cache.Key.IgnoreCase,
+		IgnoreTrailingSlash: c.Cache.Key.IgnoreTrailingSlash,
+		IncludeOrigin:       c.Cache.Key.IncludeOrigin,
+		OriginName:          originName,
 	}
 }
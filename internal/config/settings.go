@@ -0,0 +1,86 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+const redacted = "[redacted]"
+
+// Settings returns the configuration as nested maps keyed the way the config file is, for
+// display on the admin API. Fields tagged `redact:"true"` have their values replaced, keeping
+// map keys so that operators can see what is configured; fields tagged `redact:"url"` only
+// lose the password of their URLs.
+func (c Config) Settings() map[string]any {
+	return settingsOf(reflect.ValueOf(c)).(map[string]any)
+}
+
+func settingsOf(v reflect.Value) any {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct {
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = settingsOf(v.Index(i))
+		}
+		return out
+	}
+	if v.Kind() != reflect.Struct {
+		return v.Interface()
+	}
+	out := make(map[string]any, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		// viper matches untagged fields case-insensitively
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		switch field.Tag.Get("redact") {
+		case "true":
+			out[name] = redactValue(v.Field(i), func(string) string { return redacted })
+		case "url":
+			out[name] = redactValue(v.Field(i), redactURL)
+		default:
+			out[name] = settingsOf(v.Field(i))
+		}
+	}
+	return out
+}
+
+// redactValue applies redact to a string, or to every element of a string slice or value
+// of a string map. Empty strings stay empty so that unset secrets remain recognizable.
+func redactValue(v reflect.Value, redact func(string) string) any {
+	apply := func(s string) string {
+		if s == "" {
+			return s
+		}
+		return redact(s)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return apply(v.String())
+	case reflect.Slice:
+		out := make([]string, v.Len())
+		for i := range out {
+			out[i] = apply(v.Index(i).String())
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]string, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = apply(iter.Value().String())
+		}
+		return out
+	default:
+		return redacted
+	}
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// Validate rejects settings that would otherwise be silently reinterpreted or left unsafe at
+// runtime.
+func (c *Config) Validate() error {
+	switch valueobject.SetCookieMode(c.Cache.Policy.SetCookieMode) {
+	case valueobject.SetCookieSkip, valueobject.SetCookieStrip:
+	default:
+		return fmt.Errorf("cache.policy.set_cookie_mode: unknown mode %q, want skip or strip", c.Cache.Policy.SetCookieMode)
+	}
+	for code := range c.Cache.Policy.StatusTTLSeconds {
+		if status, err := strconv.Atoi(code); err != nil || status < 100 || status > 599 {
+			return fmt.Errorf("cache.policy.status_ttl_seconds: %q is not an HTTP status code", code)
+		}
+	}
+	if c.Cache.Policy.AdaptiveTTL {
+		// the TTL doubles on every unchanged revalidation, so it needs a ceiling
+		if c.Cache.Policy.MaxTTLSeconds <= 0 {
+			return fmt.Errorf("cache.policy.max_ttl_seconds is required when adaptive_ttl is on")
+		}
+		if c.Cache.Policy.MinTTLSeconds > c.Cache.Policy.MaxTTLSeconds {
+			return fmt.Errorf("cache.policy.min_ttl_seconds (%d) exceeds max_ttl_seconds (%d)", c.Cache.Policy.MinTTLSeconds, c.Cache.Policy.MaxTTLSeconds)
+		}
+	}
+	// without a server certificate there is no TLS, so a client CA would silently go unused
+	if c.Admin.ClientCAFile != "" && (c.Admin.Listen == "" || c.Admin.CertFile == "" || c.Admin.KeyFile == "") {
+		return fmt.Errorf("admin.client_ca_file requires admin.listen, admin.cert_file and admin.key_file")
+	}
+	if c.ServerTiming.TriggerHeader != "" && c.ServerTiming.TriggerValue == "" {
+		return fmt.Errorf("server_timing.trigger_value is required when trigger_header is set")
+	}
+	if c.Origin.Retry.MaxDelayMs < c.Origin.Retry.BaseDelayMs {
+		return fmt.Errorf("origin.retry.max_delay_ms (%d) is below base_delay_ms (%d)", c.Origin.Retry.MaxDelayMs, c.Origin.Retry.BaseDelayMs)
+	}
+	if write := time.Duration(c.Server.WriteTimeoutSeconds) * time.Second; write > 0 {
+		if fetch := c.Origin.longestFetch(); fetch > 0 && write <= fetch {
+			return fmt.Errorf("server.write_timeout_seconds: %s would cut off origin fetches that may take %s with retries, backoff and queueing", write, fetch)
+		}
+	}
+	return nil
+}
+
+// longestFetch is the longest an origin fetch may take across every attempt, including the
+// waits for a concurrency slot and the backoff between attempts. It is zero when an
+// attempt has no total timeout and so no bound.
+func (o OriginConfig) longestFetch() time.Duration {
+	attempt := o.Timeouts.TotalMs
+	for _, route := range o.Timeouts.Routes {
+		if route.TotalMs <= 0 {
+			return 0
+		}
+		attempt = max(attempt, route.TotalMs)
+	}
+	if o.Timeouts.TotalMs <= 0 {
+		return 0
+	}
+	if o.Concurrency.MaxInFlight > 0 {
+		attempt += o.Concurrency.QueueTimeoutMs
+	}
+	attempts := int64(max(o.Retry.MaxAttempts, 1))
+	return time.Duration(attempts*attempt+(attempts-1)*o.Retry.MaxDelayMs) * time.Millisecond
+}
@@ -0,0 +1,144 @@
+package config
+
+import "testing"
+
+func TestValidateWriteTimeoutCoversOriginFetch(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  ServerConfig
+		origin  OriginConfig
+		wantErr bool
+	}{
+		{
+			name:   "defaults",
+			server: ServerConfig{WriteTimeoutSeconds: 90},
+			origin: OriginConfig{Timeouts: OriginTimeoutsConfig{TotalMs: 30000}, Retry: OriginRetryConfig{MaxAttempts: 1, MaxDelayMs: 1000}},
+		},
+		{
+			name:    "single attempt longer than the write timeout",
+			server:  ServerConfig{WriteTimeoutSeconds: 10},
+			origin:  OriginConfig{Timeouts: OriginTimeoutsConfig{TotalMs: 30000}},
+			wantErr: true,
+		},
+		{
+			name:    "retries exceed the write timeout",
+			server:  ServerConfig{WriteTimeoutSeconds: 90},
+			origin:  OriginConfig{Timeouts: OriginTimeoutsConfig{TotalMs: 30000}, Retry: OriginRetryConfig{MaxAttempts: 3, MaxDelayMs: 1000}},
+			wantErr: true,
+		},
+		{
+			name:   "retries with backoff fit",
+			server: ServerConfig{WriteTimeoutSeconds: 93},
+			origin: OriginConfig{Timeouts: OriginTimeoutsConfig{TotalMs: 30000}, Retry: OriginRetryConfig{MaxAttempts: 3, MaxDelayMs: 1000}},
+		},
+		{
+			name:   "queue timeout without a concurrency limit is ignored",
+			server: ServerConfig{WriteTimeoutSeconds: 31},
+			origin: OriginConfig{Timeouts: OriginTimeoutsConfig{TotalMs: 30000}, Concurrency: OriginConcurrencyConfig{QueueTimeoutMs: 5000}},
+		},
+		{
+			name:    "queue timeout counts with a concurrency limit",
+			server:  ServerConfig{WriteTimeoutSeconds: 31},
+			origin:  OriginConfig{Timeouts: OriginTimeoutsConfig{TotalMs: 30000}, Concurrency: OriginConcurrencyConfig{MaxInFlight: 10, QueueTimeoutMs: 5000}},
+			wantErr: true,
+		},
+		{
+			name:    "longest route timeout counts",
+			server:  ServerConfig{WriteTimeoutSeconds: 90},
+			origin:  OriginConfig{Timeouts: OriginTimeoutsConfig{TotalMs: 30000, Routes: []RouteTimeoutConfig{{PathPrefix: "/reports", TotalMs: 120000}}}},
+			wantErr: true,
+		},
+		{
+			name:   "write timeout disabled",
+			origin: OriginConfig{Timeouts: OriginTimeoutsConfig{TotalMs: 30000}, Retry: OriginRetryConfig{MaxAttempts: 5}},
+		},
+		{
+			name:   "unbounded origin attempts cannot be checked",
+			server: ServerConfig{WriteTimeoutSeconds: 10},
+			origin: OriginConfig{Timeouts: OriginTimeoutsConfig{TotalMs: 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Server: tt.server, Origin: tt.origin}
+			cfg.Cache.Policy.SetCookieMode = "skip"
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAdaptiveTTLBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  PolicyConfig
+		wantErr bool
+	}{
+		{name: "adaptive off needs no bounds", policy: PolicyConfig{}},
+		{name: "adaptive without max", policy: PolicyConfig{AdaptiveTTL: true, MinTTLSeconds: 10}, wantErr: true},
+		{name: "adaptive with min above max", policy: PolicyConfig{AdaptiveTTL: true, MinTTLSeconds: 600, MaxTTLSeconds: 60}, wantErr: true},
+		{name: "adaptive with bounds", policy: PolicyConfig{AdaptiveTTL: true, MinTTLSeconds: 10, MaxTTLSeconds: 3600}},
+		{name: "adaptive with only max", policy: PolicyConfig{AdaptiveTTL: true, MaxTTLSeconds: 3600}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			cfg.Cache.Policy = tt.policy
+			cfg.Cache.Policy.SetCookieMode = "skip"
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStatusTTLCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		wantErr bool
+	}{
+		{name: "status code", code: "404"},
+		{name: "lowest status", code: "100"},
+		{name: "highest status", code: "599"},
+		{name: "class wildcard", code: "4xx", wantErr: true},
+		{name: "trailing space", code: "404 ", wantErr: true},
+		{name: "below range", code: "99", wantErr: true},
+		{name: "above range", code: "600", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			cfg.Cache.Policy.SetCookieMode = "skip"
+			cfg.Cache.Policy.StatusTTLSeconds = map[string]int64{tt.code: 30}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRetryDelays(t *testing.T) {
+	tests := []struct {
+		name    string
+		retry   OriginRetryConfig
+		wantErr bool
+	}{
+		{name: "no delays", retry: OriginRetryConfig{MaxAttempts: 3}},
+		{name: "max above base", retry: OriginRetryConfig{MaxAttempts: 3, BaseDelayMs: 50, MaxDelayMs: 1000}},
+		{name: "max equal to base", retry: OriginRetryConfig{MaxAttempts: 3, BaseDelayMs: 50, MaxDelayMs: 50}},
+		{name: "base without max", retry: OriginRetryConfig{MaxAttempts: 3, BaseDelayMs: 50}, wantErr: true},
+		{name: "max below base", retry: OriginRetryConfig{MaxAttempts: 3, BaseDelayMs: 500, MaxDelayMs: 100}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			cfg.Cache.Policy.SetCookieMode = "skip"
+			cfg.Origin.Retry = tt.retry
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package contract
+
+import "github.com/mikiasgoitom/RevProx/internal/domain/entity"
+
+// IAccessLogger writes one line per served request to the access log sink.
+type IAccessLogger interface {
+	Log(entry entity.AccessLogEntry)
+	Close() error
+}
@@ -0,0 +1,13 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+// IAuditLogger writes admin actions to the audit trail.
+type IAuditLogger interface {
+	Record(ctx context.Context, event entity.AuditEvent)
+	Close() error
+}
@@ -0,0 +1,12 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+type ICacheInspectUseCase interface {
+	List(ctx context.Context, query entity.CacheEntryQuery) entity.CacheEntryPage
+	Lookup(ctx context.Context, key string, includeBody bool) (entity.CacheEntryInfo, bool)
+}
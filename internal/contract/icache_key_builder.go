@@ -0,0 +1,10 @@
+package contract
+
+import (
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+type ICacheKeyBuilder interface {
+	Build(req entity.RequestModel) valueobject.CacheKey
+}
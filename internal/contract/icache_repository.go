@@ -9,6 +9,21 @@ import (
 
 type ICacheRepository interface {
 	Get(ctx context.Context, key valueobject.CacheKey) (entity.CacheEntry, bool, error)
-	Set(ctx context.Context,value entity.CacheEntry) error
+	// Peek reads the response stored under key like Get, but without counting a hit or
+	// affecting which entries are evicted, for dry runs.
+	Peek(ctx context.Context, key valueobject.CacheKey) (entity.CacheEntry, bool, error)
+	Set(ctx context.Context, value entity.CacheEntry) error
+	GetMetadata(ctx context.Context, key valueobject.CacheKey) (entity.CacheMetadata, bool, error)
+	SetMetadata(ctx context.Context, meta entity.CacheMetadata) error
+	// Delete removes the response stored under key and its metadata, reporting whether a
+	// response was present.
+	Delete(ctx context.Context, key valueobject.CacheKey) (bool, error)
+	// List pages through the cached responses matching query, ordered by key string.
+	List(ctx context.Context, query entity.CacheEntryQuery) entity.CacheEntryPage
+	// Entries returns every cached response, for snapshots.
+	Entries(ctx context.Context) []entity.CacheEntry
+	// Lookup describes the response stored under the key string without counting a hit.
+	Lookup(ctx context.Context, key string, includeBody bool) (entity.CacheEntryInfo, bool)
 	HealthCheck(ctx context.Context) error
+	ICacheStatsProvider
 }
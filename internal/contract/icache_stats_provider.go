@@ -0,0 +1,11 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+type ICacheStatsProvider interface {
+	Stats(ctx context.Context) entity.CacheStats
+}
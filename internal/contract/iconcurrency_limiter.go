@@ -0,0 +1,17 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+type IConcurrencyLimiter interface {
+	// Acquire waits in FIFO order for a free slot. It fails with an overloaded
+	// entity.OriginError when the queue is full or the wait exceeds the queue timeout, and
+	// with the context's error when ctx ends first. release must be called exactly once with
+	// the request's latency and whether it failed.
+	Acquire(ctx context.Context) (release func(latency time.Duration, failed bool), err error)
+	Stats() entity.ConcurrencyStats
+}
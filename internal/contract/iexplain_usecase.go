@@ -0,0 +1,13 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+type IExplainUseCase interface {
+	// Explain dry-runs req without storing anything. When nothing is cached and fetch is set
+	// the origin is asked for a response to evaluate.
+	Explain(ctx context.Context, req entity.RequestModel, fetch bool) entity.CacheExplanation
+}
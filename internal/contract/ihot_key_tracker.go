@@ -0,0 +1,13 @@
+package contract
+
+import (
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// IHotKeyTracker keeps an approximate, bounded-memory ranking of the heaviest keys.
+type IHotKeyTracker interface {
+	Record(key string, sample entity.HotKeySample, now int64)
+	// Top returns at most n keys ordered by the given dimension, heaviest first.
+	Top(by valueobject.HotKeyDimension, n int) []entity.HotKey
+}
@@ -3,13 +3,47 @@ package contract
 import (
 	"context"
 	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
 )
 
 type IMetricsAdapter interface {
 	IncHit(ctx context.Context) error
 	IncMiss(ctx context.Context) error
-	RecordEviction(ctx context.Context) error
+	// RecordEviction counts an entry leaving the cache; key is zero when it is unknown.
+	RecordEviction(ctx context.Context, reason valueobject.EvictionReason, key valueobject.CacheKey) error
 	RecordUpstreamLatency(ctx context.Context, latency time.Duration) error
+	RecordUpstreamPhase(ctx context.Context, phase string, latency time.Duration) error
 	RecordCacheLatency(ctx context.Context, latency time.Duration) error
 	RecordTotalLatency(ctx context.Context, latency time.Duration) error
+	// RecordRequest counts one served request of bytes response body bytes; route must be a
+	// route template, not a raw path.
+	RecordRequest(ctx context.Context, route string, method string, status int, outcome valueobject.CacheOutcome, bytes int, latency time.Duration) error
+	IncOriginInFlight(ctx context.Context) error
+	DecOriginInFlight(ctx context.Context) error
+	RecordOriginError(ctx context.Context, kind string) error
+	// RecordOriginConnection counts the connection an origin request was sent on, taken from
+	// the idle pool or newly dialed, and the protocol spoken on it, whether or not a response
+	// followed.
+	RecordOriginConnection(ctx context.Context, reused bool, protocol string) error
+	// RecordOriginQueueWait records how long a request waited for an origin slot and whether
+	// it was "admitted", "shed" or "canceled".
+	RecordOriginQueueWait(ctx context.Context, outcome string, wait time.Duration) error
+	// RecordOriginRetry counts a retry decision for a failed origin attempt; reason is the
+	// error kind or "status_<code>", outcome "retried" or "budget_exhausted".
+	RecordOriginRetry(ctx context.Context, reason string, outcome string) error
+	// RecordOriginHedge counts a hedged origin request as "won", "lost" or "budget_exhausted".
+	RecordOriginHedge(ctx context.Context, outcome string) error
+	// RecordRateLimited counts a request rejected by the rate limit rule named rule.
+	RecordRateLimited(ctx context.Context, rule string, key valueobject.RateLimitKey) error
+	// ObserveCache exports the provider's size and internal counters on every scrape.
+	ObserveCache(provider ICacheStatsProvider)
+	// ObserveHotKeys exports the top entries of both rankings on every scrape.
+	ObserveHotKeys(keys IHotKeyTracker, clients IHotKeyTracker, top int)
+	// ObserveOriginLimiter exports the limit and queue depth of upstream's limiter on every
+	// scrape. It is called once per upstream, which is named by its position: 0 for
+	// origin_url, then origin.upstreams in order.
+	ObserveOriginLimiter(upstream string, limiter IConcurrencyLimiter)
+	// ObserveRateLimiter exports the number of tracked buckets on every scrape.
+	ObserveRateLimiter(limiter IRateLimiter)
 }
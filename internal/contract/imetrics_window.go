@@ -0,0 +1,12 @@
+package contract
+
+import (
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+// IMetricsWindow summarises recorded metrics over recent sliding windows.
+type IMetricsWindow interface {
+	Snapshot(window time.Duration) entity.Metrics
+}
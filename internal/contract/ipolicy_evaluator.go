@@ -1,7 +1,10 @@
 package contract
 
-import "github.com/mikiasgoitom/RevProx/internal/domain/entity"
+import (
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
 
 type IPolicyEvaluator interface {
-	Evaluate(resp entity.ResponseModel, req entity.RequestModel, cachePolicy entity.CachePolicy) (bool, int64)
+	Evaluate(resp entity.ResponseModel, req entity.RequestModel, cachePolicy entity.CachePolicy) entity.CacheDecision
+	ShouldBypass(req entity.RequestModel, cachePolicy entity.CachePolicy) bool
 }
@@ -0,0 +1,10 @@
+package contract
+
+import "context"
+
+type IPurgeUseCase interface {
+	// Purge removes the response stored under the exact key string.
+	Purge(ctx context.Context, key string) (int, error)
+	// PurgePrefix removes every response whose key string starts with prefix.
+	PurgePrefix(ctx context.Context, prefix string) (int, error)
+}
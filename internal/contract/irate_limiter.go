@@ -0,0 +1,15 @@
+package contract
+
+import (
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+type IRateLimiter interface {
+	// Allow takes one token from the bucket identified by key.
+	Allow(key string, limit valueobject.RateLimit, now time.Time) entity.RateLimitDecision
+	// Len reports how many buckets are held in memory.
+	Len() int
+}
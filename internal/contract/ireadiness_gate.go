@@ -0,0 +1,6 @@
+package contract
+
+// IReadinessGate holds readiness back until a component has finished starting up.
+type IReadinessGate interface {
+	Ready() error
+}
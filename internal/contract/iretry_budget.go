@@ -0,0 +1,10 @@
+package contract
+
+import "time"
+
+type IRetryBudget interface {
+	// Deposit credits the budget for one original request.
+	Deposit(now time.Time)
+	// Withdraw takes one retry from the budget and reports whether one was available.
+	Withdraw(now time.Time) bool
+}
@@ -0,0 +1,14 @@
+package contract
+
+import (
+	"io"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+// ISnapshotArchive encodes cache entries to and from a snapshot file.
+type ISnapshotArchive interface {
+	Write(w io.Writer, info entity.SnapshotInfo, entries []entity.CacheEntry) error
+	// Read calls visit for every entry in the archive, in the order they were written.
+	Read(r io.Reader, visit func(entity.CacheEntry) error) (entity.SnapshotInfo, error)
+}
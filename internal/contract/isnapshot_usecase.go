@@ -0,0 +1,15 @@
+package contract
+
+import (
+	"context"
+	"io"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+type ISnapshotUseCase interface {
+	// Export writes every cached response to w and returns how many were written.
+	Export(ctx context.Context, w io.Writer) (int, error)
+	// Import stores the entries of the archive read from r, skipping expired ones.
+	Import(ctx context.Context, r io.Reader) (entity.SnapshotImport, error)
+}
@@ -0,0 +1,14 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+type IStatsUseCase interface {
+	Stats(ctx context.Context) entity.StatsReport
+	HotKeys(ctx context.Context, by valueobject.HotKeyDimension, limit int) []entity.HotKey
+	HotClients(ctx context.Context, by valueobject.HotKeyDimension, limit int) []entity.HotKey
+}
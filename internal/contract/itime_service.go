@@ -5,4 +5,7 @@ import "time"
 type ITimeService interface {
 	Now() time.Time
 	NowUnix() int64
+	// Monotonic returns a reading suitable only for measuring elapsed time with Since.
+	Monotonic() time.Time
+	Since(start time.Time) time.Duration
 }
@@ -0,0 +1,22 @@
+package contract
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+type ITracer interface {
+	Start(ctx context.Context, name string, kind valueobject.SpanKind, attrs ...valueobject.LogField) (context.Context, ISpan)
+	// Extract continues a trace from incoming W3C traceparent/tracestate headers.
+	Extract(ctx context.Context, headers http.Header) context.Context
+	// Inject writes the current trace context into outgoing headers.
+	Inject(ctx context.Context, headers http.Header)
+}
+
+type ISpan interface {
+	SetAttributes(attrs ...valueobject.LogField)
+	RecordError(err error)
+	End()
+}
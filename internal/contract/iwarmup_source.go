@@ -0,0 +1,9 @@
+package contract
+
+import "context"
+
+// IWarmupSource produces seed URLs, as origin paths with an optional query, for cache warming.
+type IWarmupSource interface {
+	Name() string
+	URLs(ctx context.Context) ([]string, error)
+}
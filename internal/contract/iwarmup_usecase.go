@@ -0,0 +1,20 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+type IWarmupUseCase interface {
+	// Start warms the cache in the background from the configured sources plus extra URLs.
+	// It returns false when a run is already in progress.
+	Start(ctx context.Context, trigger string, extra []string) bool
+	// Run warms the cache and returns once every URL has been attempted.
+	Run(ctx context.Context, trigger string, extra []string) (entity.WarmupProgress, bool)
+	// Schedule starts a run every interval until ctx is done.
+	Schedule(ctx context.Context, interval time.Duration)
+	Progress() entity.WarmupProgress
+	IReadinessGate
+}
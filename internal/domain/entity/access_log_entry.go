@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// AccessLogEntry describes one served request for the access log.
+type AccessLogEntry struct {
+	Time      time.Time
+	RequestID string
+	ClientIP  string
+	Method    string
+	URI       string
+	Proto     string
+	Route     string
+	Status    int
+	// Bytes is the size of the response body sent to the client.
+	Bytes       int
+	Referer     string
+	UserAgent   string
+	Duration    time.Duration
+	CacheStatus valueobject.CacheOutcome
+	// UpstreamAddr, UpstreamTime and UpstreamBytes are empty when the origin was not
+	// contacted. UpstreamBytes is the size of the body read from the origin.
+	UpstreamAddr  string
+	UpstreamTime  time.Duration
+	UpstreamBytes int
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// AuditEvent records one call to the admin API: who made it, what it did and when.
+type AuditEvent struct {
+	Time       time.Time
+	Actor      string
+	RemoteAddr string
+	RequestID  string
+	Method     string
+	Path       string
+	Query      string
+	Status     int
+	Allowed    bool
+	// DenyReason explains a rejected call, e.g. "missing bearer token".
+	DenyReason string
+}
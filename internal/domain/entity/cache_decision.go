@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// CacheDecision explains whether a response may be stored and for how long.
+type CacheDecision struct {
+	Cacheable bool
+	// ExpiresAt is the absolute expiry as a unix timestamp; zero when not cacheable.
+	ExpiresAt int64
+	TTL       time.Duration
+	Freshness valueobject.FreshnessSource
+	// Rule names the directive or policy rule that decided, e.g. "max-age", "no-store" or
+	// "status-ttl".
+	Rule   string
+	Reason string
+	// Warnings point out things that did not decide the outcome but may surprise, such as
+	// ignored or malformed headers.
+	Warnings []string
+}
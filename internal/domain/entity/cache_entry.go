@@ -9,4 +9,7 @@ type CacheEntry struct {
 	Payload   ResponseModel
 	ExpiresAt int64
 	StoredAt  int64
+	// StaleUntil keeps the entry around past ExpiresAt so it can be revalidated with the origin.
+	StaleUntil int64
+	Freshness  valueobject.FreshnessSource
 }
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// CacheEntryInfo describes a cached response for inspection.
+type CacheEntryInfo struct {
+	Key        valueobject.CacheKey
+	Size       int64
+	StoredAt   int64
+	ExpiresAt  int64
+	StaleUntil int64
+	// Hits counts cache reads of this key since it was first stored.
+	Hits    uint64
+	Status  int
+	Headers http.Header
+	// Body is only filled in when the caller asks for it.
+	Body []byte
+}
+
+// CacheEntryQuery selects a page of cached responses ordered by key string.
+type CacheEntryQuery struct {
+	Prefix  string
+	Pattern *regexp.Regexp
+	// After is the last key of the previous page; empty starts from the beginning.
+	After string
+	// Limit caps the page size; zero returns every match.
+	Limit       int
+	IncludeBody bool
+}
+
+// CacheEntryPage is one page of a listing. NextCursor is empty on the last page.
+type CacheEntryPage struct {
+	Entries    []CacheEntryInfo
+	Total      int
+	NextCursor string
+}
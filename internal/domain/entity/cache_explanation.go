@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// CacheExplanation is the result of dry-running a request through key normalization,
+// cache lookup and the cache policy.
+type CacheExplanation struct {
+	Key    valueobject.CacheKey
+	Bypass bool
+	// State is "fresh" or "stale" when an entry is cached and empty otherwise.
+	State      string
+	StoredAt   int64
+	ExpiresAt  int64
+	StaleUntil int64
+	Freshness  valueobject.FreshnessSource
+	// AdaptiveTTL and BackoffUntil come from the key's metadata when it has any.
+	AdaptiveTTL  time.Duration
+	BackoffUntil int64
+	// Source tells where the evaluated response came from: "cache", "origin" or empty
+	// when no response was available.
+	Source       string
+	OriginStatus int
+	FetchError   string
+	Decision     *CacheDecision
+}
@@ -0,0 +1,15 @@
+package entity
+
+// CacheKeyPolicy controls which parts of a request make up its cache key.
+type CacheKeyPolicy struct {
+	// QueryAllowlist, when set, keeps only matching query parameters. Patterns use path.Match syntax.
+	QueryAllowlist []string
+	// QueryDenylist drops matching query parameters, e.g. "utm_*".
+	QueryDenylist       []string
+	Headers             []string
+	Cookies             []string
+	IgnoreCase          bool
+	IgnoreTrailingSlash bool
+	IncludeOrigin       bool
+	OriginName          string
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// CacheMetadata is per-key bookkeeping that outlives the cached entry itself, so that
+// adaptive TTLs can be carried across expirations.
+type CacheMetadata struct {
+	Key       valueobject.CacheKey
+	BodyHash  string
+	TTL       time.Duration
+	History   []TTLChange
+	UpdatedAt int64
+	ExpiresAt int64
+	// BackoffUntil is set when the origin answered 429/503 with Retry-After; until then
+	// requests for the key are answered from cache or with BackoffResponse.
+	BackoffUntil    int64
+	BackoffResponse *ResponseModel
+}
+
+// TTLChange records one adaptive TTL adjustment.
+type TTLChange struct {
+	At      int64
+	TTL     time.Duration
+	Changed bool
+}
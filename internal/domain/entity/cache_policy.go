@@ -7,8 +7,27 @@ import (
 )
 
 type CachePolicy struct {
-	DefaultTTL valueobject.TTL
-	RespectNoCache bool
-	RespectNoStore bool
+	DefaultTTL       valueobject.TTL
+	RespectNoCache   bool
+	RespectNoStore   bool
 	RevalidateWindow time.Duration
-}
\ No newline at end of file
+	// HeuristicFraction is the share of the time since Last-Modified used as freshness
+	// lifetime when the origin sends no explicit one. Zero disables heuristic freshness.
+	HeuristicFraction float64
+	HeuristicMaxTTL   time.Duration
+	SetCookieMode     valueobject.SetCookieMode
+	// BypassCookies names request cookies whose presence skips the cache entirely.
+	BypassCookies []string
+	// StatusTTLs gives per status code lifetimes for negative caching; listed codes become
+	// cacheable even when they are not cacheable by default (e.g. 503).
+	StatusTTLs map[int]time.Duration
+	// MaxRetryAfter caps how long an origin Retry-After can make a key back off.
+	MaxRetryAfter time.Duration
+	// BackoffOn429 treats the origin's 429 responses as a limit shared by all clients, so
+	// they start a backoff like a 503 does. Otherwise a 429 only concerns the client that
+	// caused it.
+	BackoffOn429 bool
+	// StaleIfOverloaded answers requests shed by the origin concurrency limit from a stale
+	// entry when there is one.
+	StaleIfOverloaded bool
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// SnapshotInfo describes a snapshot archive.
+type SnapshotInfo struct {
+	Version   int
+	CreatedAt time.Time
+	Entries   int
+}
+
+// SnapshotImport reports what loading an archive did.
+type SnapshotImport struct {
+	Archive SnapshotInfo
+	// Loaded entries were stored; Dropped ones were accepted but then turned away by the
+	// cache's admission policy or evicted by later entries; Expired ones had outlived their
+	// stale window; Failed ones were refused by the cache, e.g. for lack of room.
+	Loaded  int
+	Dropped int
+	Expired int
+	Failed  int
+}
@@ -0,0 +1,17 @@
+package entity
+
+// CacheStats is a point-in-time view of the cache store's size and internal counters.
+type CacheStats struct {
+	Entries      uint64
+	Bytes        uint64
+	MaxBytes     uint64
+	Hits         uint64
+	Misses       uint64
+	KeysAdded    uint64
+	KeysUpdated  uint64
+	KeysEvicted  uint64
+	SetsDropped  uint64
+	SetsRejected uint64
+	GetsDropped  uint64
+	GetsKept     uint64
+}
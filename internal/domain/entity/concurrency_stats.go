@@ -0,0 +1,8 @@
+package entity
+
+// ConcurrencyStats is a point-in-time view of an upstream concurrency limiter.
+type ConcurrencyStats struct {
+	Limit    int
+	InFlight int
+	Queued   int
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// HotKeySample is what one served request contributes to a key's or client's counters.
+type HotKeySample struct {
+	Requests   uint64
+	Misses     uint64
+	Bytes      uint64
+	OriginTime time.Duration
+}
+
+// HotKey is an estimated tally for a frequently seen key or client. Counts come from a
+// count-min sketch and may be overestimated, never underestimated.
+type HotKey struct {
+	Key        string
+	Requests   uint64
+	Misses     uint64
+	Bytes      uint64
+	OriginTime time.Duration
+}
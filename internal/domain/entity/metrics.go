@@ -2,11 +2,54 @@ package entity
 
 import "time"
 
+// Metrics summarises proxy activity over a sliding window ending now.
 type Metrics struct {
-	Hits            uint64
-	Misses          uint64
-	UpstreamLatency time.Duration
-	CacheLatency    time.Duration
-	TotalLatency    time.Duration	
+	Window   time.Duration
+	Requests uint64
+	Hits     uint64
+	Misses   uint64
+	HitRatio float64
+	// BytesServed counts proxied response bodies; BytesFromCache the part of them that was
+	// answered from the cache (hits, stale and revalidated responses).
+	BytesServed     uint64
+	BytesFromCache  uint64
+	ByteHitRatio    float64
+	UpstreamLatency LatencySummary
+	CacheLatency    LatencySummary
+	TotalLatency    LatencySummary
 	Evictions       uint64
+	// TopEvictedPrefixes lists the path prefixes that lost the most entries, largest first.
+	TopEvictedPrefixes []PrefixCount
+	OriginRequests     uint64
+	OriginErrors       map[string]uint64
+	OriginErrorRate    float64
+	OriginRetries      uint64
+	// OriginConnsReused and OriginConnsNew count origin responses by whether their
+	// connection came from the idle pool.
+	OriginConnsReused uint64
+	OriginConnsNew    uint64
+	// RateLimited counts requests rejected with 429 by the rate limiter.
+	RateLimited uint64
+}
+
+// LatencySummary holds estimated percentiles of a latency distribution.
+type LatencySummary struct {
+	Count uint64
+	P50   time.Duration
+	P90   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+type PrefixCount struct {
+	Prefix string
+	Count  uint64
+}
+
+// StatsReport is the live view served by the admin stats endpoint.
+type StatsReport struct {
+	GeneratedAt time.Time
+	Cache       CacheStats
+	Windows     []Metrics
 }
@@ -0,0 +1,31 @@
+package entity
+
+import "fmt"
+
+// Kinds of origin failures, used to label error metrics.
+const (
+	OriginErrorTimeout    = "timeout"
+	OriginErrorDNS        = "dns"
+	OriginErrorConnection = "connection"
+	OriginErrorTLS        = "tls"
+	OriginErrorRead       = "read"
+	OriginErrorStatus5xx  = "status_5xx"
+	OriginErrorOther      = "other"
+	// OriginErrorOverloaded marks requests shed by the upstream concurrency limiter without
+	// reaching the origin.
+	OriginErrorOverloaded = "overloaded"
+)
+
+// OriginError is returned by origin repositories so callers can tell failures apart.
+type OriginError struct {
+	Kind string
+	Err  error
+}
+
+func (e *OriginError) Error() string {
+	return fmt.Sprintf("origin %s error: %v", e.Kind, e.Err)
+}
+
+func (e *OriginError) Unwrap() error {
+	return e.Err
+}
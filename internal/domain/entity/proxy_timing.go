@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// ProxyTiming is how long the proxy spent in each step of serving one request. Steps that
+// did not happen are zero.
+type ProxyTiming struct {
+	CacheLookup time.Duration
+	OriginFetch time.Duration
+	CacheWrite  time.Duration
+	Total       time.Duration
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// RateLimitDecision is the outcome of taking one request from a token bucket.
+type RateLimitDecision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long a rejected client should wait for the next token.
+	RetryAfter time.Duration
+}
@@ -13,4 +13,7 @@ type RequestModel struct {
 	Headers    http.Header
 	Body       []byte
 	ReceivedAt int64
+	// Warmup marks requests issued by cache warm-up. They are kept out of the traffic
+	// metrics and hot key rankings, which describe client traffic.
+	Warmup bool
 }
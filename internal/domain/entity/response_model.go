@@ -2,6 +2,8 @@ package entity
 
 import (
 	"net/http"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
 )
 
 type ResponseModel struct {
@@ -11,4 +13,10 @@ type ResponseModel struct {
 	Body        []byte
 	GeneratedAt int64
 	Cacheable   bool
+	// Timing is only set on responses fetched from the origin.
+	Timing UpstreamTiming
+	// ProxyTiming is measured for the current request and never stored.
+	ProxyTiming ProxyTiming
+	// CacheOutcome tells how the proxy produced this response for the current request.
+	CacheOutcome valueobject.CacheOutcome
 }
@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// UpstreamTiming breaks an origin round trip into its phases. Phases that did not happen,
+// such as DNS and connect on a reused connection, are zero.
+type UpstreamTiming struct {
+	// Addr is the remote address of the connection the request went out on.
+	Addr string
+	// ConnReused tells whether the connection came from the idle pool rather than a new dial.
+	ConnReused bool
+	// Bytes is the size of the response body read from the origin.
+	Bytes int
+	// Total spans from sending the request to reading the last body byte.
+	Total   time.Duration
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// TTFB spans from writing the last request byte to reading the first response byte.
+	TTFB     time.Duration
+	Transfer time.Duration
+}
+
+type UpstreamPhase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Phases lists the phases that took place, in request order.
+func (t UpstreamTiming) Phases() []UpstreamPhase {
+	all := []UpstreamPhase{
+		{Name: "dns", Duration: t.DNS},
+		{Name: "connect", Duration: t.Connect},
+		{Name: "tls", Duration: t.TLS},
+		{Name: "ttfb", Duration: t.TTFB},
+		{Name: "transfer", Duration: t.Transfer},
+	}
+	phases := make([]UpstreamPhase, 0, len(all))
+	for _, p := range all {
+		if p.Duration > 0 {
+			phases = append(phases, p)
+		}
+	}
+	return phases
+}
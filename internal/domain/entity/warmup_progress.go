@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// WarmupProgress reports the current or last cache warm-up run.
+type WarmupProgress struct {
+	Running bool
+	// Trigger is what started the run: "startup", "schedule" or "admin".
+	Trigger    string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Sources    []WarmupSourceResult
+	// Total is the number of distinct URLs to fetch; Done counts those already attempted.
+	Total int
+	Done  int
+	// AlreadyCached were in the cache (fresh or stale), Stored were fetched and cached, Uncacheable were fetched but
+	// not cacheable and Failed produced an error or a 5xx response.
+	AlreadyCached int
+	Stored        int
+	Uncacheable   int
+	Failed        int
+}
+
+// WarmupSourceResult tells how many seed URLs a source produced.
+type WarmupSourceResult struct {
+	Name  string
+	URLs  int
+	Error string
+}
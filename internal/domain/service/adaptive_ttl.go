@@ -0,0 +1,37 @@
+package domainservice
+
+import (
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// maxTTLHistory bounds the number of adjustments kept per key.
+const maxTTLHistory = 10
+
+// AdjustAdaptiveTTL doubles the key's TTL when the origin content was unchanged and halves it
+// when it changed, keeping the result within the policy's Min/Max. A key seen for the first
+// time starts at the policy duration.
+func AdjustAdaptiveTTL(ttl valueobject.TTL, meta entity.CacheMetadata, known bool, changed bool, now int64) entity.CacheMetadata {
+	next := ttl.Duration
+	if known && meta.TTL > 0 {
+		if changed {
+			next = meta.TTL / 2
+		} else {
+			next = meta.TTL * 2
+		}
+	}
+	next = ttl.Clamp(next)
+	if next < time.Second {
+		next = time.Second
+	}
+
+	meta.TTL = next
+	meta.UpdatedAt = now
+	meta.History = append(meta.History, entity.TTLChange{At: now, TTL: next, Changed: changed})
+	if len(meta.History) > maxTTLHistory {
+		meta.History = meta.History[len(meta.History)-maxTTLHistory:]
+	}
+	return meta
+}
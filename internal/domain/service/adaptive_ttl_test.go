@@ -0,0 +1,67 @@
+package domainservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+func TestAdjustAdaptiveTTL(t *testing.T) {
+	policy := valueobject.TTL{Duration: 10 * time.Second, Adaptive: true, Min: 4 * time.Second, Max: time.Minute}
+
+	tests := []struct {
+		name    string
+		ttl     valueobject.TTL
+		meta    entity.CacheMetadata
+		known   bool
+		changed bool
+		want    time.Duration
+	}{
+		{name: "first sighting starts at the policy duration", ttl: policy, want: 10 * time.Second},
+		{name: "known key without a TTL starts at the policy duration", ttl: policy, known: true, want: 10 * time.Second},
+		{name: "unchanged doubles", ttl: policy, meta: entity.CacheMetadata{TTL: 10 * time.Second}, known: true, want: 20 * time.Second},
+		{name: "changed halves", ttl: policy, meta: entity.CacheMetadata{TTL: 20 * time.Second}, known: true, changed: true, want: 10 * time.Second},
+		{name: "doubling is capped at Max", ttl: policy, meta: entity.CacheMetadata{TTL: 40 * time.Second}, known: true, want: time.Minute},
+		{name: "halving stops at Min", ttl: policy, meta: entity.CacheMetadata{TTL: 6 * time.Second}, known: true, changed: true, want: 4 * time.Second},
+		{name: "first sighting is clamped too", ttl: valueobject.TTL{Duration: 2 * time.Hour, Max: time.Minute}, want: time.Minute},
+		{
+			name:    "never below one second",
+			ttl:     valueobject.TTL{Duration: time.Second, Max: time.Minute},
+			meta:    entity.CacheMetadata{TTL: time.Second},
+			known:   true,
+			changed: true,
+			want:    time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AdjustAdaptiveTTL(tt.ttl, tt.meta, tt.known, tt.changed, 100)
+			if got.TTL != tt.want {
+				t.Fatalf("TTL = %s, want %s", got.TTL, tt.want)
+			}
+			if got.UpdatedAt != 100 {
+				t.Errorf("UpdatedAt = %d, want 100", got.UpdatedAt)
+			}
+			last := got.History[len(got.History)-1]
+			if last != (entity.TTLChange{At: 100, TTL: tt.want, Changed: tt.changed}) {
+				t.Errorf("last history entry = %+v, want the adjustment to %s", last, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdjustAdaptiveTTLTrimsHistory(t *testing.T) {
+	policy := valueobject.TTL{Duration: 10 * time.Second, Adaptive: true, Min: time.Second, Max: time.Hour}
+	var meta entity.CacheMetadata
+	for i := 0; i < 15; i++ {
+		meta = AdjustAdaptiveTTL(policy, meta, i > 0, i%2 == 1, int64(i))
+	}
+	if len(meta.History) != maxTTLHistory {
+		t.Fatalf("history holds %d entries, want %d", len(meta.History), maxTTLHistory)
+	}
+	if meta.History[0].At != 5 || meta.History[maxTTLHistory-1].At != 14 {
+		t.Fatalf("history spans %d..%d, want the latest adjustments 5..14", meta.History[0].At, meta.History[maxTTLHistory-1].At)
+	}
+}
@@ -0,0 +1,145 @@
+package domainservice
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+type CacheKeyBuilder struct {
+	policy entity.CacheKeyPolicy
+}
+
+func NewCacheKeyBuilder(policy entity.CacheKeyPolicy) contract.ICacheKeyBuilder {
+	headers := make([]string, len(policy.Headers))
+	for i, h := range policy.Headers {
+		headers[i] = http.CanonicalHeaderKey(h)
+	}
+	sort.Strings(headers)
+	cookies := append([]string(nil), policy.Cookies...)
+	sort.Strings(cookies)
+	policy.Headers = headers
+	policy.Cookies = cookies
+	return &CacheKeyBuilder{policy: policy}
+}
+
+func (b *CacheKeyBuilder) Build(req entity.RequestModel) valueobject.CacheKey {
+	key := valueobject.CacheKey{
+		Method:        req.Method,
+		NormalizedURL: b.normalizeURL(req.URL),
+		Variant:       b.variant(req.Headers),
+	}
+	if b.policy.IncludeOrigin {
+		key.Namespace = b.policy.OriginName
+	}
+	return key
+}
+
+// normalizeURL drops the fragment, lowercases the host, cleans the path and filters and
+// sorts the query according to the policy.
+func (b *CacheKeyBuilder) normalizeURL(reqURL *url.URL) string {
+	if reqURL == nil {
+		return ""
+	}
+	u := *reqURL
+	u.Fragment = ""
+
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port != "" {
+		if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+			u.Host = host
+		} else {
+			u.Host = host + ":" + port
+		}
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	} else {
+		trailingSlash := strings.HasSuffix(u.Path, "/")
+		u.Path = path.Clean(u.Path)
+		if !strings.HasPrefix(u.Path, "/") {
+			u.Path = "/" + u.Path
+		}
+		if trailingSlash && !b.policy.IgnoreTrailingSlash && u.Path != "/" {
+			u.Path += "/"
+		}
+	}
+	if b.policy.IgnoreCase {
+		u.Path = strings.ToLower(u.Path)
+	}
+	u.RawPath = ""
+
+	queryParams := u.Query()
+	keys := make([]string, 0, len(queryParams))
+	for k := range queryParams {
+		if b.keepQueryParam(k) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		u.RawQuery = ""
+		return u.String()
+	}
+	sort.Strings(keys)
+
+	out := url.Values{}
+	for _, k := range keys {
+		vals := queryParams[k]
+		sort.Strings(vals)
+		for _, v := range vals {
+			out.Add(k, v)
+		}
+	}
+	u.RawQuery = out.Encode()
+	return u.String()
+}
+
+func (b *CacheKeyBuilder) keepQueryParam(name string) bool {
+	if len(b.policy.QueryAllowlist) > 0 && !matchesAny(b.policy.QueryAllowlist, name) {
+		return false
+	}
+	return !matchesAny(b.policy.QueryDenylist, name)
+}
+
+// variant renders the selected headers and cookies in a stable order. Values are query
+// escaped so that a value containing the separators cannot forge another variant.
+func (b *CacheKeyBuilder) variant(headers http.Header) string {
+	if len(b.policy.Headers) == 0 && len(b.policy.Cookies) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(b.policy.Headers)+len(b.policy.Cookies))
+	for _, name := range b.policy.Headers {
+		values := headers.Values(name)
+		escaped := make([]string, len(values))
+		for i, v := range values {
+			escaped[i] = url.QueryEscape(v)
+		}
+		parts = append(parts, "h:"+name+"="+strings.Join(escaped, ","))
+	}
+	httpReq := http.Request{Header: headers}
+	for _, name := range b.policy.Cookies {
+		value := ""
+		if cookie, err := httpReq.Cookie(name); err == nil {
+			value = cookie.Value
+		}
+		parts = append(parts, "c:"+name+"="+url.QueryEscape(value))
+	}
+	return strings.Join(parts, "&")
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
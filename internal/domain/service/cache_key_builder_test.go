@@ -0,0 +1,119 @@
+package domainservice
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+func TestCacheKeyBuilderBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  entity.CacheKeyPolicy
+		method  string
+		url     string
+		headers http.Header
+		want    valueobject.CacheKey
+	}{
+		{
+			name:   "drops fragment and default port and lowercases host",
+			url:    "http://Example.COM:80/a/b#top",
+			method: http.MethodGet,
+			want:   valueobject.CacheKey{Method: http.MethodGet, NormalizedURL: "http://example.com/a/b"},
+		},
+		{
+			name:   "keeps non-default port",
+			url:    "https://example.com:8443/a",
+			method: http.MethodGet,
+			want:   valueobject.CacheKey{Method: http.MethodGet, NormalizedURL: "https://example.com:8443/a"},
+		},
+		{
+			name:   "cleans path and keeps trailing slash",
+			url:    "http://example.com/a//b/../c/",
+			method: http.MethodGet,
+			want:   valueobject.CacheKey{Method: http.MethodGet, NormalizedURL: "http://example.com/a/c/"},
+		},
+		{
+			name:   "folds trailing slash",
+			policy: entity.CacheKeyPolicy{IgnoreTrailingSlash: true},
+			url:    "http://example.com/a/",
+			method: http.MethodGet,
+			want:   valueobject.CacheKey{Method: http.MethodGet, NormalizedURL: "http://example.com/a"},
+		},
+		{
+			name:   "ignores path case",
+			policy: entity.CacheKeyPolicy{IgnoreCase: true},
+			url:    "http://example.com/A/b",
+			method: http.MethodGet,
+			want:   valueobject.CacheKey{Method: http.MethodGet, NormalizedURL: "http://example.com/a/b"},
+		},
+		{
+			name:   "sorts query parameters and values",
+			url:    "http://example.com/?b=2&a=2&a=1",
+			method: http.MethodGet,
+			want:   valueobject.CacheKey{Method: http.MethodGet, NormalizedURL: "http://example.com/?a=1&a=2&b=2"},
+		},
+		{
+			name:   "drops denied query parameters",
+			policy: entity.CacheKeyPolicy{QueryDenylist: []string{"utm_*"}},
+			url:    "http://example.com/?utm_source=x&utm_medium=y&id=1",
+			method: http.MethodGet,
+			want:   valueobject.CacheKey{Method: http.MethodGet, NormalizedURL: "http://example.com/?id=1"},
+		},
+		{
+			name:   "keeps only allowed query parameters",
+			policy: entity.CacheKeyPolicy{QueryAllowlist: []string{"id"}},
+			url:    "http://example.com/?id=1&session=abc",
+			method: http.MethodGet,
+			want:   valueobject.CacheKey{Method: http.MethodGet, NormalizedURL: "http://example.com/?id=1"},
+		},
+		{
+			name:    "renders headers and cookies in a stable order",
+			policy:  entity.CacheKeyPolicy{Headers: []string{"x-b", "accept-language"}, Cookies: []string{"theme"}},
+			url:     "http://example.com/",
+			method:  http.MethodGet,
+			headers: http.Header{"Accept-Language": {"en"}, "X-B": {"1", "2"}, "Cookie": {"theme=dark; other=1"}},
+			want:    valueobject.CacheKey{Method: http.MethodGet, NormalizedURL: "http://example.com/", Variant: "h:Accept-Language=en&h:X-B=1,2&c:theme=dark"},
+		},
+		{
+			name:    "escapes variant values",
+			policy:  entity.CacheKeyPolicy{Headers: []string{"X-A", "X-B"}},
+			url:     "http://example.com/",
+			method:  http.MethodGet,
+			headers: http.Header{"X-A": {"1&h:X-B=2"}},
+			want:    valueobject.CacheKey{Method: http.MethodGet, NormalizedURL: "http://example.com/", Variant: "h:X-A=1%26h%3AX-B%3D2&h:X-B="},
+		},
+		{
+			name:   "namespaces by origin",
+			policy: entity.CacheKeyPolicy{IncludeOrigin: true, OriginName: "api"},
+			url:    "http://example.com/",
+			method: http.MethodHead,
+			want:   valueobject.CacheKey{Namespace: "api", Method: http.MethodHead, NormalizedURL: "http://example.com/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatalf("parse %q: %v", tt.url, err)
+			}
+			got := NewCacheKeyBuilder(tt.policy).Build(entity.RequestModel{Method: tt.method, URL: u, Headers: tt.headers})
+			if got != tt.want {
+				t.Errorf("Build() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheKeyBuilderVariantsDoNotCollide(t *testing.T) {
+	builder := NewCacheKeyBuilder(entity.CacheKeyPolicy{Headers: []string{"X-A", "X-B"}})
+	u, _ := url.Parse("http://example.com/")
+	forged := builder.Build(entity.RequestModel{Method: http.MethodGet, URL: u, Headers: http.Header{"X-A": {"1&h:X-B=2"}}})
+	honest := builder.Build(entity.RequestModel{Method: http.MethodGet, URL: u, Headers: http.Header{"X-A": {"1"}, "X-B": {"2"}}})
+	if forged == honest {
+		t.Fatalf("distinct headers produced the same key %+v", forged)
+	}
+}
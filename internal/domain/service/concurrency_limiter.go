@@ -0,0 +1,126 @@
+package domainservice
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// limitDecrease is the multiplicative decrease applied to an adaptive limit on congestion.
+const limitDecrease = 0.9
+
+var errQueueFull = errors.New("origin request queue is full")
+
+// ConcurrencyLimiter caps the requests in flight to one upstream and queues the rest in
+// arrival order. Slots are handed directly to the oldest waiter on release, so a burst of
+// new requests cannot overtake the queue.
+type ConcurrencyLimiter struct {
+	cfg valueobject.ConcurrencyLimit
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	queue    list.List
+}
+
+// limiterWaiter is a queued request; granted is set under the lock when a slot is handed
+// over and ready is closed to wake it.
+type limiterWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+func NewConcurrencyLimiter(cfg valueobject.ConcurrencyLimit) contract.IConcurrencyLimiter {
+	cfg.Max = max(cfg.Max, 1)
+	cfg.Min = min(max(cfg.Min, 1), cfg.Max)
+	return &ConcurrencyLimiter{cfg: cfg, limit: float64(cfg.Max)}
+}
+
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context) (func(time.Duration, bool), error) {
+	l.mu.Lock()
+	if l.inFlight < int(l.limit) && l.queue.Len() == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		return l.releaseFunc(), nil
+	}
+	if l.queue.Len() >= l.cfg.MaxQueue {
+		l.mu.Unlock()
+		return nil, &entity.OriginError{Kind: entity.OriginErrorOverloaded, Err: errQueueFull}
+	}
+	w := &limiterWaiter{ready: make(chan struct{})}
+	elem := l.queue.PushBack(w)
+	l.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if l.cfg.QueueTimeout > 0 {
+		timer := time.NewTimer(l.cfg.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	var err error
+	select {
+	case <-w.ready:
+		return l.releaseFunc(), nil
+	case <-timeout:
+		err = &entity.OriginError{Kind: entity.OriginErrorOverloaded, Err: fmt.Errorf("timed out after %s in the origin request queue", l.cfg.QueueTimeout)}
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.granted {
+		// the slot was handed over while we were giving up; keep it
+		return l.releaseFunc(), nil
+	}
+	l.queue.Remove(elem)
+	return nil, err
+}
+
+func (l *ConcurrencyLimiter) Stats() entity.ConcurrencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return entity.ConcurrencyStats{Limit: int(l.limit), InFlight: l.inFlight, Queued: l.queue.Len()}
+}
+
+func (l *ConcurrencyLimiter) releaseFunc() func(time.Duration, bool) {
+	var once sync.Once
+	return func(latency time.Duration, failed bool) {
+		once.Do(func() { l.release(latency, failed) })
+	}
+}
+
+func (l *ConcurrencyLimiter) release(latency time.Duration, failed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cfg.Adaptive {
+		l.adapt(latency, failed)
+	}
+	l.inFlight--
+	for l.inFlight < int(l.limit) && l.queue.Len() > 0 {
+		w := l.queue.Remove(l.queue.Front()).(*limiterWaiter)
+		w.granted = true
+		l.inFlight++
+		close(w.ready)
+	}
+}
+
+// adapt applies AIMD to the limit. It only grows while the limit is actually being used,
+// so a quiet period does not leave it at a level that was never tested. The caller must
+// hold the lock.
+func (l *ConcurrencyLimiter) adapt(latency time.Duration, failed bool) {
+	if failed || (l.cfg.LatencyThreshold > 0 && latency > l.cfg.LatencyThreshold) {
+		l.limit = max(l.limit*limitDecrease, float64(l.cfg.Min))
+		return
+	}
+	if float64(l.inFlight) >= l.limit/2 {
+		l.limit = min(l.limit+1/l.limit, float64(l.cfg.Max))
+	}
+}
@@ -0,0 +1,217 @@
+package domainservice
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// waitQueued waits until n requests are queued on the limiter.
+func waitQueued(t *testing.T, l *ConcurrencyLimiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for l.Stats().Queued != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("queued = %d, want %d", l.Stats().Queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func isOverloadedErr(err error) bool {
+	var originErr *entity.OriginError
+	return errors.As(err, &originErr) && originErr.Kind == entity.OriginErrorOverloaded
+}
+
+func TestConcurrencyLimiterHandsSlotsOverInArrivalOrder(t *testing.T) {
+	l := NewConcurrencyLimiter(valueobject.ConcurrencyLimit{Max: 1, MaxQueue: 10}).(*ConcurrencyLimiter)
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const waiters = 5
+	order := make(chan int, waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			release, err := l.Acquire(context.Background())
+			if err != nil {
+				t.Error(err)
+				order <- -1
+				return
+			}
+			order <- i
+			release(0, false)
+		}()
+		waitQueued(t, l, i+1)
+	}
+	release(0, false)
+	for want := 0; want < waiters; want++ {
+		if got := <-order; got != want {
+			t.Fatalf("waiter %d acquired in position %d", got, want)
+		}
+	}
+	if stats := l.Stats(); stats.InFlight != 0 || stats.Queued != 0 {
+		t.Fatalf("stats after all releases = %+v, want nothing in flight or queued", stats)
+	}
+}
+
+func TestConcurrencyLimiterNewRequestsQueueBehindWaiters(t *testing.T) {
+	l := NewConcurrencyLimiter(valueobject.ConcurrencyLimit{Max: 2, MaxQueue: 10}).(*ConcurrencyLimiter)
+	first, _ := l.Acquire(context.Background())
+	second, _ := l.Acquire(context.Background())
+
+	granted := make(chan struct{})
+	go func() {
+		release, err := l.Acquire(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		close(granted)
+		release(0, false)
+	}()
+	waitQueued(t, l, 1)
+
+	first(0, false)
+	<-granted
+	if stats := l.Stats(); stats.Queued != 0 {
+		t.Fatalf("queued = %d after the slot was handed over, want 0", stats.Queued)
+	}
+	second(0, false)
+}
+
+func TestConcurrencyLimiterRejectsWhenQueueIsFull(t *testing.T) {
+	l := NewConcurrencyLimiter(valueobject.ConcurrencyLimit{Max: 1, MaxQueue: 0}).(*ConcurrencyLimiter)
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release(0, false)
+
+	if _, err := l.Acquire(context.Background()); !isOverloadedErr(err) {
+		t.Fatalf("Acquire with a full queue = %v, want an overloaded origin error", err)
+	}
+}
+
+func TestConcurrencyLimiterQueueTimeout(t *testing.T) {
+	l := NewConcurrencyLimiter(valueobject.ConcurrencyLimit{Max: 1, MaxQueue: 1, QueueTimeout: 10 * time.Millisecond}).(*ConcurrencyLimiter)
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release(0, false)
+
+	if _, err := l.Acquire(context.Background()); !isOverloadedErr(err) {
+		t.Fatalf("Acquire past the queue timeout = %v, want an overloaded origin error", err)
+	}
+	if stats := l.Stats(); stats.Queued != 0 {
+		t.Fatalf("queued = %d after the timeout, want 0", stats.Queued)
+	}
+}
+
+func TestConcurrencyLimiterContextCancel(t *testing.T) {
+	l := NewConcurrencyLimiter(valueobject.ConcurrencyLimit{Max: 1, MaxQueue: 1}).(*ConcurrencyLimiter)
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := l.Acquire(ctx)
+		done <- err
+	}()
+	waitQueued(t, l, 1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire after cancel = %v, want context.Canceled", err)
+	}
+	if stats := l.Stats(); stats.Queued != 0 {
+		t.Fatalf("queued = %d after the cancel, want 0", stats.Queued)
+	}
+	release(0, false)
+	if stats := l.Stats(); stats.InFlight != 0 {
+		t.Fatalf("in flight = %d, want the slot returned rather than handed to the cancelled waiter", stats.InFlight)
+	}
+}
+
+func TestConcurrencyLimiterReleaseIsIdempotent(t *testing.T) {
+	l := NewConcurrencyLimiter(valueobject.ConcurrencyLimit{Max: 2}).(*ConcurrencyLimiter)
+	release, _ := l.Acquire(context.Background())
+	other, _ := l.Acquire(context.Background())
+	release(0, false)
+	release(0, false)
+	if got := l.Stats().InFlight; got != 1 {
+		t.Fatalf("in flight after a double release = %d, want 1", got)
+	}
+	other(0, false)
+}
+
+func TestConcurrencyLimiterAdaptive(t *testing.T) {
+	cfg := valueobject.ConcurrencyLimit{Max: 10, Min: 2, MaxQueue: 10, Adaptive: true, LatencyThreshold: 100 * time.Millisecond}
+
+	tests := []struct {
+		name string
+		// start is the limit before the requests
+		start float64
+		// inFlight requests are acquired, then the first is released with latency and failed
+		inFlight int
+		latency  time.Duration
+		failed   bool
+		want     float64
+	}{
+		{name: "failure decreases by a tenth", start: 10, inFlight: 1, failed: true, want: 9},
+		{name: "slow response decreases by a tenth", start: 10, inFlight: 1, latency: time.Second, want: 9},
+		{name: "decrease stops at the minimum", start: 2.1, inFlight: 1, failed: true, want: 2},
+		{name: "fast response under load increases by one over the limit", start: 8, inFlight: 4, latency: time.Millisecond, want: 8.125},
+		{name: "fast response without load leaves the limit", start: 8, inFlight: 3, latency: time.Millisecond, want: 8},
+		{name: "increase stops at the maximum", start: 9.95, inFlight: 5, latency: time.Millisecond, want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewConcurrencyLimiter(cfg).(*ConcurrencyLimiter)
+			l.limit = tt.start
+			var releases []func(time.Duration, bool)
+			for i := 0; i < tt.inFlight; i++ {
+				release, err := l.Acquire(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				releases = append(releases, release)
+			}
+			releases[0](tt.latency, tt.failed)
+			l.mu.Lock()
+			got := l.limit
+			l.mu.Unlock()
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("limit = %v, want %v", got, tt.want)
+			}
+			for _, release := range releases[1:] {
+				release(0, false)
+			}
+		})
+	}
+}
+
+func TestConcurrencyLimiterDecreaseQueuesNewRequests(t *testing.T) {
+	l := NewConcurrencyLimiter(valueobject.ConcurrencyLimit{Max: 2, Min: 1, MaxQueue: 1, QueueTimeout: 10 * time.Millisecond, Adaptive: true}).(*ConcurrencyLimiter)
+	first, _ := l.Acquire(context.Background())
+	second, _ := l.Acquire(context.Background())
+	// 2 * 0.9 rounds the limit down to one slot, which the second request still holds
+	first(0, true)
+	if _, err := l.Acquire(context.Background()); !isOverloadedErr(err) {
+		t.Fatalf("Acquire after the limit shrank = %v, want an overloaded origin error", err)
+	}
+	second(0, false)
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire with a free slot = %v", err)
+	}
+	release(0, false)
+}
@@ -0,0 +1,52 @@
+package domainservice
+
+import "hash/maphash"
+
+// countMinSketch estimates per-key sums in fixed memory. Estimates can exceed the true
+// value because of hash collisions but are never lower.
+type countMinSketch struct {
+	width uint64
+	seeds []maphash.Seed
+	rows  [][]uint64
+}
+
+func newCountMinSketch(width, depth int) *countMinSketch {
+	s := &countMinSketch{width: uint64(width), seeds: make([]maphash.Seed, depth), rows: make([][]uint64, depth)}
+	for i := range s.rows {
+		s.seeds[i] = maphash.MakeSeed()
+		s.rows[i] = make([]uint64, width)
+	}
+	return s
+}
+
+// add increments key by delta (conservative update) and returns the new estimate.
+func (s *countMinSketch) add(key string, delta uint64) uint64 {
+	estimate := s.estimate(key) + delta
+	for i, row := range s.rows {
+		cell := &row[maphash.String(s.seeds[i], key)%s.width]
+		if *cell < estimate {
+			*cell = estimate
+		}
+	}
+	return estimate
+}
+
+func (s *countMinSketch) estimate(key string) uint64 {
+	var min uint64
+	for i, row := range s.rows {
+		v := row[maphash.String(s.seeds[i], key)%s.width]
+		if i == 0 || v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// halve ages every counter so that old traffic fades out of the ranking.
+func (s *countMinSketch) halve() {
+	for _, row := range s.rows {
+		for j := range row {
+			row[j] /= 2
+		}
+	}
+}
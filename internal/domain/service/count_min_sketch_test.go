@@ -0,0 +1,80 @@
+package domainservice
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	tests := []struct {
+		name  string
+		width int
+		depth int
+		adds  map[string]uint64
+	}{
+		{name: "single key", width: 64, depth: 4, adds: map[string]uint64{"a": 5}},
+		{name: "distinct keys without collisions", width: 1024, depth: 4, adds: map[string]uint64{"a": 3, "b": 7, "c": 1}},
+		{name: "one column collides everything", width: 1, depth: 2, adds: map[string]uint64{"a": 3, "b": 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newCountMinSketch(tt.width, tt.depth)
+			var total uint64
+			for key, n := range tt.adds {
+				for i := uint64(0); i < n; i++ {
+					s.add(key, 1)
+				}
+				total += n
+			}
+			for key, n := range tt.adds {
+				got := s.estimate(key)
+				if got < n {
+					t.Errorf("estimate(%q) = %d, below the true count %d", key, got, n)
+				}
+				if got > total {
+					t.Errorf("estimate(%q) = %d, above the total %d", key, got, total)
+				}
+			}
+		})
+	}
+}
+
+func TestCountMinSketchAddReturnsEstimate(t *testing.T) {
+	s := newCountMinSketch(256, 4)
+	if got := s.add("a", 3); got != 3 {
+		t.Fatalf("add = %d, want 3", got)
+	}
+	if got := s.add("a", 4); got != 7 {
+		t.Fatalf("add = %d, want 7", got)
+	}
+	if got := s.estimate("unseen"); got > 7 {
+		t.Fatalf("estimate(unseen) = %d, want at most 7", got)
+	}
+}
+
+func TestCountMinSketchConservativeUpdateBoundsError(t *testing.T) {
+	// with many keys in a small sketch, a rarely seen key must still not be inflated beyond
+	// the heaviest one
+	s := newCountMinSketch(16, 4)
+	for i := 0; i < 100; i++ {
+		s.add("heavy", 1)
+	}
+	for i := 0; i < 50; i++ {
+		s.add("k"+strconv.Itoa(i), 1)
+	}
+	if got := s.estimate("heavy"); got < 100 {
+		t.Fatalf("estimate(heavy) = %d, want at least 100", got)
+	}
+	if got := s.estimate("k0"); got > 101 {
+		t.Fatalf("estimate(k0) = %d, want at most 101", got)
+	}
+}
+
+func TestCountMinSketchHalve(t *testing.T) {
+	s := newCountMinSketch(256, 4)
+	s.add("a", 9)
+	s.halve()
+	if got := s.estimate("a"); got != 4 {
+		t.Fatalf("estimate after halve = %d, want 4", got)
+	}
+}
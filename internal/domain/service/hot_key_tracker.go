@@ -0,0 +1,163 @@
+package domainservice
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// HotKeyTracker ranks keys per dimension with a count-min sketch for the estimates and a
+// min-heap holding the current top candidates. Memory is bounded by the sketch size and
+// capacity regardless of how many distinct keys are seen.
+type HotKeyTracker struct {
+	mu        sync.Mutex
+	capacity  int
+	decay     time.Duration
+	lastDecay int64
+	sketches  map[valueobject.HotKeyDimension]*countMinSketch
+	tops      map[valueobject.HotKeyDimension]*topKHeap
+}
+
+// NewHotKeyTracker keeps the capacity heaviest keys per dimension. When decay is positive
+// all counts are halved once per decay period so the ranking follows current traffic.
+func NewHotKeyTracker(capacity, sketchWidth, sketchDepth int, decay time.Duration) (contract.IHotKeyTracker, error) {
+	if sketchWidth <= 0 || sketchDepth <= 0 {
+		return nil, fmt.Errorf("hot key sketch width and depth must be positive, got %dx%d", sketchWidth, sketchDepth)
+	}
+	t := &HotKeyTracker{
+		capacity: capacity,
+		decay:    decay,
+		sketches: make(map[valueobject.HotKeyDimension]*countMinSketch, len(valueobject.HotKeyDimensions)),
+		tops:     make(map[valueobject.HotKeyDimension]*topKHeap, len(valueobject.HotKeyDimensions)),
+	}
+	for _, dim := range valueobject.HotKeyDimensions {
+		t.sketches[dim] = newCountMinSketch(sketchWidth, sketchDepth)
+		t.tops[dim] = &topKHeap{index: make(map[string]int)}
+	}
+	return t, nil
+}
+
+func (t *HotKeyTracker) Record(key string, sample entity.HotKeySample, now int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maybeDecay(now)
+	t.add(valueobject.HotKeyByRequests, key, sample.Requests)
+	t.add(valueobject.HotKeyByMisses, key, sample.Misses)
+	t.add(valueobject.HotKeyByBytes, key, sample.Bytes)
+	t.add(valueobject.HotKeyByOriginTime, key, uint64(sample.OriginTime/time.Microsecond))
+}
+
+func (t *HotKeyTracker) add(dim valueobject.HotKeyDimension, key string, delta uint64) {
+	if delta == 0 {
+		return
+	}
+	estimate := t.sketches[dim].add(key, delta)
+	top := t.tops[dim]
+	if i, ok := top.index[key]; ok {
+		top.items[i].value = estimate
+		heap.Fix(top, i)
+		return
+	}
+	if top.Len() < t.capacity {
+		heap.Push(top, topKItem{key: key, value: estimate})
+		return
+	}
+	if top.Len() > 0 && estimate > top.items[0].value {
+		delete(top.index, top.items[0].key)
+		top.items[0] = topKItem{key: key, value: estimate}
+		top.index[key] = 0
+		heap.Fix(top, 0)
+	}
+}
+
+func (t *HotKeyTracker) maybeDecay(now int64) {
+	if t.decay <= 0 {
+		return
+	}
+	if t.lastDecay == 0 {
+		t.lastDecay = now
+		return
+	}
+	if now-t.lastDecay < int64(t.decay/time.Second) {
+		return
+	}
+	t.lastDecay = now
+	for dim, sketch := range t.sketches {
+		sketch.halve()
+		// halving keeps the heap order, so no re-heapify is needed
+		top := t.tops[dim]
+		for i := range top.items {
+			top.items[i].value /= 2
+		}
+	}
+}
+
+func (t *HotKeyTracker) Top(by valueobject.HotKeyDimension, n int) []entity.HotKey {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	top, ok := t.tops[by]
+	if !ok {
+		return nil
+	}
+	items := append([]topKItem(nil), top.items...)
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].value != items[j].value {
+			return items[i].value > items[j].value
+		}
+		return items[i].key < items[j].key
+	})
+	if n > 0 && len(items) > n {
+		items = items[:n]
+	}
+	keys := make([]entity.HotKey, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, entity.HotKey{
+			Key:        item.key,
+			Requests:   t.sketches[valueobject.HotKeyByRequests].estimate(item.key),
+			Misses:     t.sketches[valueobject.HotKeyByMisses].estimate(item.key),
+			Bytes:      t.sketches[valueobject.HotKeyByBytes].estimate(item.key),
+			OriginTime: time.Duration(t.sketches[valueobject.HotKeyByOriginTime].estimate(item.key)) * time.Microsecond,
+		})
+	}
+	return keys
+}
+
+type topKItem struct {
+	key   string
+	value uint64
+}
+
+// topKHeap is a min-heap on value so the weakest candidate is evicted first; index maps
+// keys to their heap position.
+type topKHeap struct {
+	items []topKItem
+	index map[string]int
+}
+
+func (h *topKHeap) Len() int           { return len(h.items) }
+func (h *topKHeap) Less(i, j int) bool { return h.items[i].value < h.items[j].value }
+
+func (h *topKHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].key] = i
+	h.index[h.items[j].key] = j
+}
+
+func (h *topKHeap) Push(x any) {
+	item := x.(topKItem)
+	h.index[item.key] = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *topKHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.index, last.key)
+	return last
+}
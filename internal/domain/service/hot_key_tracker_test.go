@@ -0,0 +1,160 @@
+package domainservice
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+func TestNewHotKeyTrackerRejectsEmptySketch(t *testing.T) {
+	tests := []struct {
+		name         string
+		width, depth int
+		wantErr      bool
+	}{
+		{name: "valid", width: 64, depth: 4},
+		{name: "zero width", width: 0, depth: 4, wantErr: true},
+		{name: "zero depth", width: 64, depth: 0, wantErr: true},
+		{name: "negative width", width: -1, depth: 4, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHotKeyTracker(10, tt.width, tt.depth, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewHotKeyTracker error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHotKeyTrackerTop(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		records  []string
+		by       valueobject.HotKeyDimension
+		n        int
+		want     []string
+	}{
+		{
+			name:     "orders by count",
+			capacity: 10,
+			records:  []string{"a", "b", "b", "c", "c", "c"},
+			by:       valueobject.HotKeyByRequests,
+			want:     []string{"c", "b", "a"},
+		},
+		{
+			name:     "breaks ties by key",
+			capacity: 10,
+			records:  []string{"b", "a"},
+			by:       valueobject.HotKeyByRequests,
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "limits to n",
+			capacity: 10,
+			records:  []string{"a", "b", "b", "c", "c", "c"},
+			by:       valueobject.HotKeyByRequests,
+			n:        2,
+			want:     []string{"c", "b"},
+		},
+		{
+			name:     "heavier key displaces the weakest at capacity",
+			capacity: 2,
+			records:  []string{"a", "a", "b", "b", "b", "c", "c", "c", "c"},
+			by:       valueobject.HotKeyByRequests,
+			want:     []string{"c", "b"},
+		},
+		{
+			name:     "lighter key does not displace at capacity",
+			capacity: 2,
+			records:  []string{"a", "a", "b", "b", "c"},
+			by:       valueobject.HotKeyByRequests,
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "unknown dimension",
+			capacity: 2,
+			records:  []string{"a"},
+			by:       valueobject.HotKeyDimension("nope"),
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, err := NewHotKeyTracker(tt.capacity, 1024, 4, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range tt.records {
+				tracker.Record(key, entity.HotKeySample{Requests: 1}, 1)
+			}
+			got := tracker.Top(tt.by, tt.n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Top() returned %d keys %v, want %v", len(got), got, tt.want)
+			}
+			for i, key := range tt.want {
+				if got[i].Key != key {
+					t.Errorf("Top()[%d] = %q, want %q", i, got[i].Key, key)
+				}
+			}
+		})
+	}
+}
+
+func TestHotKeyTrackerReportsEveryDimension(t *testing.T) {
+	tracker, err := NewHotKeyTracker(10, 1024, 4, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.Record("a", entity.HotKeySample{Requests: 1, Misses: 1, Bytes: 100, OriginTime: 20 * time.Millisecond}, 1)
+	tracker.Record("a", entity.HotKeySample{Requests: 1, Bytes: 50}, 1)
+
+	for _, dim := range valueobject.HotKeyDimensions {
+		top := tracker.Top(dim, 0)
+		if len(top) != 1 || top[0].Key != "a" {
+			t.Fatalf("Top(%s) = %v, want only a", dim, top)
+		}
+	}
+	got := tracker.Top(valueobject.HotKeyByRequests, 1)[0]
+	want := entity.HotKey{Key: "a", Requests: 2, Misses: 1, Bytes: 150, OriginTime: 20 * time.Millisecond}
+	if got != want {
+		t.Fatalf("Top() = %+v, want %+v", got, want)
+	}
+}
+
+func TestHotKeyTrackerDecay(t *testing.T) {
+	tracker, err := NewHotKeyTracker(10, 1024, 4, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 8; i++ {
+		tracker.Record("a", entity.HotKeySample{Requests: 1}, 100)
+	}
+	// within the period nothing decays; the first record after it halves the counts
+	tracker.Record("b", entity.HotKeySample{Requests: 1}, 105)
+	if got := tracker.Top(valueobject.HotKeyByRequests, 1)[0].Requests; got != 8 {
+		t.Fatalf("requests before the decay period = %d, want 8", got)
+	}
+	tracker.Record("b", entity.HotKeySample{Requests: 1}, 110)
+	top := tracker.Top(valueobject.HotKeyByRequests, 0)
+	if top[0].Key != "a" || top[0].Requests != 4 {
+		t.Fatalf("top after decay = %+v, want a with 4 requests", top[0])
+	}
+}
+
+func TestHotKeyTrackerBoundedMemory(t *testing.T) {
+	tracker, err := NewHotKeyTracker(5, 64, 4, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		tracker.Record("k"+strconv.Itoa(i), entity.HotKeySample{Requests: 1}, 1)
+	}
+	if got := len(tracker.Top(valueobject.HotKeyByRequests, 0)); got != 5 {
+		t.Fatalf("Top() returned %d keys, want the capacity of 5", got)
+	}
+}
@@ -1,12 +1,14 @@
 package domainservice
 
 import (
-	"log"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/mikiasgoitom/RevProx/internal/contract"
 	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
 	"github.com/mikiasgoitom/RevProx/pkg/cachecontrol"
 )
 
@@ -16,52 +18,157 @@ func NewPolicyEvaluator() contract.IPolicyEvaluator {
 	return &PolicyEvaluator{}
 }
 
-func (srv *PolicyEvaluator) Evaluate(resp entity.ResponseModel, req entity.RequestModel, cachePolicy entity.CachePolicy) (bool, int64) {
+// Evaluate decides whether resp may be stored, for how long and why. The returned expiry
+// is an absolute unix timestamp.
+func (srv *PolicyEvaluator) Evaluate(resp entity.ResponseModel, req entity.RequestModel, cachePolicy entity.CachePolicy) entity.CacheDecision {
+	var warnings []string
+	reject := func(rule, reason string) entity.CacheDecision {
+		return entity.CacheDecision{Rule: rule, Reason: reason, Warnings: warnings}
+	}
+	now := time.Now()
+	accept := func(rule, reason string, ttl time.Duration, freshness valueobject.FreshnessSource) entity.CacheDecision {
+		return entity.CacheDecision{
+			Cacheable: true,
+			ExpiresAt: now.Add(ttl).Unix(),
+			TTL:       ttl,
+			Freshness: freshness,
+			Rule:      rule,
+			Reason:    reason,
+			Warnings:  warnings,
+		}
+	}
+
 	if req.Method != http.MethodGet {
-		return false, 0
+		return reject("method", fmt.Sprintf("only GET responses are cached, got %s", req.Method))
+	}
+	if srv.ShouldBypass(req, cachePolicy) {
+		return reject("bypass-cookie", "the request carries one of cache.policy.bypass_cookies")
 	}
 	cc := cachecontrol.Parse(resp.Headers.Get("cache-control"))
 
-	if cachecontrol.Has(cc, "no-store") || cachecontrol.Has(cc, "private") {
-		log.Println("Cache not allowed due to no-store or private directive")
-		return false, 0
+	if cachecontrol.Has(cc, "no-store") {
+		return reject("no-store", "Cache-Control: no-store forbids storing the response")
+	}
+	if cachecontrol.Has(cc, "private") {
+		return reject("private", "Cache-Control: private restricts the response to private caches")
 	}
 
-	if !isCacheableStatusCode(resp.Status) {
-		log.Printf("Status code %d is not cacheable\n", resp.Status)
-		return false, 0
+	// RFC 9111 section 3.5: a shared cache may only store responses to authenticated
+	// requests when the origin explicitly allows it.
+	if req.Headers.Get("Authorization") != "" &&
+		!cachecontrol.Has(cc, "public") && !cachecontrol.Has(cc, "s-maxage") && !cachecontrol.Has(cc, "must-revalidate") {
+		return reject("authorization", "requests with Authorization are only cached with public, s-maxage or must-revalidate")
+	}
+
+	if len(resp.Headers.Values("Set-Cookie")) > 0 {
+		if cachePolicy.SetCookieMode != valueobject.SetCookieStrip {
+			return reject("set-cookie", "responses with Set-Cookie are not cached (cache.policy.set_cookie_mode is skip)")
+		}
+		warnings = append(warnings, "Set-Cookie is removed from the stored copy")
+	}
+	if cachecontrol.Has(cc, "no-cache") {
+		warnings = append(warnings, "no-cache is not enforced: the stored response is served without revalidation until it expires")
+	}
+	if vary := strings.TrimSpace(resp.Headers.Get("Vary")); vary != "" {
+		warnings = append(warnings, fmt.Sprintf("response varies on %q; only headers listed in cache.key.headers separate variants", vary))
+	}
+
+	statusTTL, hasStatusTTL := cachePolicy.StatusTTLs[resp.Status]
+	if !isCacheableStatusCode(resp.Status) && !hasStatusTTL {
+		return reject("status", fmt.Sprintf("status %d is not cacheable by default and has no cache.policy.status_ttl_seconds entry", resp.Status))
 	}
 
 	if sMaxAge, ok := cachecontrol.GetDuration(cc, "s-maxage"); ok {
-		ttl := int64(time.Now().Unix() + int64(sMaxAge.Seconds()))
-		log.Printf("Using s-maxage directive with duration %v and ttl %v\n", sMaxAge, ttl)
-		return true, ttl
+		if cachecontrol.Has(cc, "max-age") {
+			warnings = append(warnings, "s-maxage overrides max-age for shared caches")
+		}
+		return accept("s-maxage", fmt.Sprintf("Cache-Control: s-maxage=%d", int64(sMaxAge.Seconds())), sMaxAge, valueobject.FreshnessExplicit)
 	}
 
 	if maxAge, ok := cachecontrol.GetDuration(cc, "max-age"); ok {
-		ttl := int64(time.Now().Unix() + int64(maxAge.Seconds()))
-		log.Printf("Using max-age directive with duration %v and ttl %v\n", maxAge, ttl)
-		return true, ttl
+		if resp.Headers.Get("Expires") != "" {
+			warnings = append(warnings, "max-age overrides the Expires header")
+		}
+		return accept("max-age", fmt.Sprintf("Cache-Control: max-age=%d", int64(maxAge.Seconds())), maxAge, valueobject.FreshnessExplicit)
 	}
 
 	if expiresHeader := resp.Headers.Get("Expires"); expiresHeader != "" {
 		expireTime, err := http.ParseTime(expiresHeader)
-		if err == nil {
-			ttl := time.Until(expireTime)
-			if ttl > 0 {
-				log.Printf("Using Expires header with ttl %v\n", ttl)
-				return true, int64(ttl.Seconds())
-			} // no else because we can set out owm ttl
+		switch {
+		case err != nil:
+			warnings = append(warnings, fmt.Sprintf("ignored malformed Expires header %q", expiresHeader))
+		case !expireTime.After(now):
+			warnings = append(warnings, "ignored Expires header in the past")
+		default:
+			return accept("expires", "Expires: "+expiresHeader, expireTime.Sub(now), valueobject.FreshnessExplicit)
+		}
+	}
+
+	if hasStatusTTL {
+		if statusTTL <= 0 {
+			return reject("status-ttl", fmt.Sprintf("cache.policy.status_ttl_seconds disables caching of status %d", resp.Status))
 		}
+		return accept("status-ttl", fmt.Sprintf("cache.policy.status_ttl_seconds for status %d", resp.Status), statusTTL, valueobject.FreshnessStatus)
+	}
+
+	if freshness, ok := heuristicFreshness(resp, cachePolicy, now); ok {
+		return accept("heuristic", "no explicit lifetime; a fraction of the time since Last-Modified", freshness, valueobject.FreshnessHeuristic)
 	}
 
 	if cachePolicy.DefaultTTL.Duration > 0 {
-		return true, int64(cachePolicy.DefaultTTL.Duration.Seconds())
+		return accept("default-ttl", "no explicit lifetime; cache.policy.default_ttl_seconds", cachePolicy.DefaultTTL.Duration, valueobject.FreshnessDefault)
 	}
 
-	return false, 0
+	return reject("no-freshness", "the response has no explicit lifetime and no default TTL is configured")
+}
+
+// ShouldBypass reports whether the request carries one of the policy's bypass cookies, in
+// which case the cache is neither read nor written.
+func (srv *PolicyEvaluator) ShouldBypass(req entity.RequestModel, cachePolicy entity.CachePolicy) bool {
+	if len(cachePolicy.BypassCookies) == 0 || req.Headers == nil {
+		return false
+	}
+	httpReq := http.Request{Header: req.Headers}
+	for _, name := range cachePolicy.BypassCookies {
+		if _, err := httpReq.Cookie(name); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// heuristicFreshness implements RFC 9111 section 4.2.2: a fraction of the time elapsed since
+// Last-Modified, capped by the policy maximum. Evaluate only gets here for heuristically
+// cacheable status codes.
+func heuristicFreshness(resp entity.ResponseModel, cachePolicy entity.CachePolicy, now time.Time) (time.Duration, bool) {
+	if cachePolicy.HeuristicFraction <= 0 {
+		return 0, false
+	}
+	lastModified, err := http.ParseTime(resp.Headers.Get("Last-Modified"))
+	if err != nil {
+		return 0, false
+	}
+	date := now
+	if dateHeader, err := http.ParseTime(resp.Headers.Get("Date")); err == nil {
+		date = dateHeader
+	}
+	age := date.Sub(lastModified)
+	if age <= 0 {
+		return 0, false
+	}
+	freshness := time.Duration(float64(age) * cachePolicy.HeuristicFraction)
+	if cachePolicy.HeuristicMaxTTL > 0 && freshness > cachePolicy.HeuristicMaxTTL {
+		freshness = cachePolicy.HeuristicMaxTTL
+	}
+	if freshness < time.Second {
+		return 0, false
+	}
+	return freshness, true
 }
 
+// isCacheableStatusCode reports the status codes RFC 9110 section 15.1 defines as
+// heuristically cacheable. Other codes are only cached with a
+// cache.policy.status_ttl_seconds entry.
 func isCacheableStatusCode(statusCode int) bool {
 	switch statusCode {
 	case http.StatusOK, // 200
@@ -70,9 +177,11 @@ func isCacheableStatusCode(statusCode int) bool {
 		http.StatusPartialContent,       // 206
 		http.StatusMultipleChoices,      // 300
 		http.StatusMovedPermanently,     // 301
+		http.StatusPermanentRedirect,    // 308
 		http.StatusNotFound,             // 404
 		http.StatusMethodNotAllowed,     // 405
 		http.StatusGone,                 // 410
+		http.StatusRequestURITooLong,    // 414
 		http.StatusNotImplemented:       // 501
 		return true
 	default:
@@ -0,0 +1,249 @@
+package domainservice
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+func TestPolicyEvaluatorEvaluate(t *testing.T) {
+	date := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	defaultPolicy := entity.CachePolicy{DefaultTTL: valueobject.TTL{Duration: time.Minute}}
+
+	tests := []struct {
+		name       string
+		method     string
+		reqHeaders http.Header
+		status     int
+		headers    http.Header
+		policy     entity.CachePolicy
+		cacheable  bool
+		rule       string
+		ttl        time.Duration
+		freshness  valueobject.FreshnessSource
+	}{
+		{
+			name:    "non-GET is not cached",
+			method:  http.MethodPost,
+			status:  http.StatusOK,
+			headers: http.Header{"Cache-Control": {"max-age=60"}},
+			policy:  defaultPolicy,
+			rule:    "method",
+		},
+		{
+			name:       "bypass cookie",
+			reqHeaders: http.Header{"Cookie": {"session=1"}},
+			status:     http.StatusOK,
+			headers:    http.Header{"Cache-Control": {"max-age=60"}},
+			policy:     entity.CachePolicy{DefaultTTL: valueobject.TTL{Duration: time.Minute}, BypassCookies: []string{"session"}},
+			rule:       "bypass-cookie",
+		},
+		{
+			name:    "no-store",
+			status:  http.StatusOK,
+			headers: http.Header{"Cache-Control": {"no-store, max-age=60"}},
+			policy:  defaultPolicy,
+			rule:    "no-store",
+		},
+		{
+			name:    "private",
+			status:  http.StatusOK,
+			headers: http.Header{"Cache-Control": {"private, max-age=60"}},
+			policy:  defaultPolicy,
+			rule:    "private",
+		},
+		{
+			name:       "authorization without public",
+			reqHeaders: http.Header{"Authorization": {"Bearer x"}},
+			status:     http.StatusOK,
+			headers:    http.Header{"Cache-Control": {"max-age=60"}},
+			policy:     defaultPolicy,
+			rule:       "authorization",
+		},
+		{
+			name:       "authorization with public",
+			reqHeaders: http.Header{"Authorization": {"Bearer x"}},
+			status:     http.StatusOK,
+			headers:    http.Header{"Cache-Control": {"public, max-age=60"}},
+			policy:     defaultPolicy,
+			cacheable:  true,
+			rule:       "max-age",
+			ttl:        time.Minute,
+			freshness:  valueobject.FreshnessExplicit,
+		},
+		{
+			name:    "set-cookie in skip mode",
+			status:  http.StatusOK,
+			headers: http.Header{"Cache-Control": {"max-age=60"}, "Set-Cookie": {"a=1"}},
+			policy:  entity.CachePolicy{SetCookieMode: valueobject.SetCookieSkip},
+			rule:    "set-cookie",
+		},
+		{
+			name:      "set-cookie in strip mode",
+			status:    http.StatusOK,
+			headers:   http.Header{"Cache-Control": {"max-age=60"}, "Set-Cookie": {"a=1"}},
+			policy:    entity.CachePolicy{SetCookieMode: valueobject.SetCookieStrip},
+			cacheable: true,
+			rule:      "max-age",
+			ttl:       time.Minute,
+			freshness: valueobject.FreshnessExplicit,
+		},
+		{
+			name:    "uncacheable status",
+			status:  http.StatusInternalServerError,
+			headers: http.Header{"Cache-Control": {"max-age=60"}},
+			policy:  defaultPolicy,
+			rule:    "status",
+		},
+		{
+			name:      "s-maxage wins over max-age",
+			status:    http.StatusOK,
+			headers:   http.Header{"Cache-Control": {"max-age=60, s-maxage=120"}},
+			policy:    defaultPolicy,
+			cacheable: true,
+			rule:      "s-maxage",
+			ttl:       2 * time.Minute,
+			freshness: valueobject.FreshnessExplicit,
+		},
+		{
+			name:      "max-age",
+			status:    http.StatusOK,
+			headers:   http.Header{"Cache-Control": {"max-age=30"}},
+			cacheable: true,
+			rule:      "max-age",
+			ttl:       30 * time.Second,
+			freshness: valueobject.FreshnessExplicit,
+		},
+		{
+			name:      "expires in the past falls back to the default TTL",
+			status:    http.StatusOK,
+			headers:   http.Header{"Expires": {date.Format(http.TimeFormat)}},
+			policy:    defaultPolicy,
+			cacheable: true,
+			rule:      "default-ttl",
+			ttl:       time.Minute,
+			freshness: valueobject.FreshnessDefault,
+		},
+		{
+			name:      "status ttl makes 503 cacheable",
+			status:    http.StatusServiceUnavailable,
+			policy:    entity.CachePolicy{StatusTTLs: map[int]time.Duration{http.StatusServiceUnavailable: 5 * time.Second}},
+			cacheable: true,
+			rule:      "status-ttl",
+			ttl:       5 * time.Second,
+			freshness: valueobject.FreshnessStatus,
+		},
+		{
+			name:   "zero status ttl disables caching",
+			status: http.StatusNotFound,
+			policy: entity.CachePolicy{DefaultTTL: valueobject.TTL{Duration: time.Minute}, StatusTTLs: map[int]time.Duration{http.StatusNotFound: 0}},
+			rule:   "status-ttl",
+		},
+		{
+			name:   "heuristic freshness from Last-Modified",
+			status: http.StatusOK,
+			headers: http.Header{
+				"Date":          {date.Format(http.TimeFormat)},
+				"Last-Modified": {date.Add(-10 * time.Hour).Format(http.TimeFormat)},
+			},
+			policy:    entity.CachePolicy{HeuristicFraction: 0.1},
+			cacheable: true,
+			rule:      "heuristic",
+			ttl:       time.Hour,
+			freshness: valueobject.FreshnessHeuristic,
+		},
+		{
+			name:   "heuristic freshness is capped",
+			status: http.StatusOK,
+			headers: http.Header{
+				"Date":          {date.Format(http.TimeFormat)},
+				"Last-Modified": {date.Add(-10 * time.Hour).Format(http.TimeFormat)},
+			},
+			policy:    entity.CachePolicy{HeuristicFraction: 0.1, HeuristicMaxTTL: 10 * time.Minute},
+			cacheable: true,
+			rule:      "heuristic",
+			ttl:       10 * time.Minute,
+			freshness: valueobject.FreshnessHeuristic,
+		},
+		{
+			name:      "308 is heuristically cacheable",
+			status:    http.StatusPermanentRedirect,
+			policy:    defaultPolicy,
+			cacheable: true,
+			rule:      "default-ttl",
+			ttl:       time.Minute,
+			freshness: valueobject.FreshnessDefault,
+		},
+		{
+			name:      "414 is heuristically cacheable",
+			status:    http.StatusRequestURITooLong,
+			policy:    defaultPolicy,
+			cacheable: true,
+			rule:      "default-ttl",
+			ttl:       time.Minute,
+			freshness: valueobject.FreshnessDefault,
+		},
+		{
+			name:   "no freshness information",
+			status: http.StatusOK,
+			rule:   "no-freshness",
+		},
+	}
+	evaluator := NewPolicyEvaluator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			reqHeaders := tt.reqHeaders
+			if reqHeaders == nil {
+				reqHeaders = http.Header{}
+			}
+			headers := tt.headers
+			if headers == nil {
+				headers = http.Header{}
+			}
+			got := evaluator.Evaluate(
+				entity.ResponseModel{Status: tt.status, Headers: headers},
+				entity.RequestModel{Method: method, Headers: reqHeaders},
+				tt.policy,
+			)
+			if got.Cacheable != tt.cacheable || got.Rule != tt.rule {
+				t.Fatalf("Evaluate() = cacheable %v rule %q (%s), want cacheable %v rule %q", got.Cacheable, got.Rule, got.Reason, tt.cacheable, tt.rule)
+			}
+			if got.TTL != tt.ttl || got.Freshness != tt.freshness {
+				t.Errorf("Evaluate() = ttl %s freshness %q, want ttl %s freshness %q", got.TTL, got.Freshness, tt.ttl, tt.freshness)
+			}
+			if tt.cacheable && got.ExpiresAt <= time.Now().Unix() {
+				t.Errorf("ExpiresAt = %d, want a time in the future", got.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestPolicyEvaluatorShouldBypass(t *testing.T) {
+	tests := []struct {
+		name    string
+		cookies []string
+		headers http.Header
+		want    bool
+	}{
+		{name: "no bypass cookies configured", headers: http.Header{"Cookie": {"session=1"}}},
+		{name: "no headers", cookies: []string{"session"}},
+		{name: "matching cookie", cookies: []string{"session"}, headers: http.Header{"Cookie": {"theme=dark; session=1"}}, want: true},
+		{name: "other cookies only", cookies: []string{"session"}, headers: http.Header{"Cookie": {"theme=dark"}}},
+	}
+	evaluator := NewPolicyEvaluator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluator.ShouldBypass(entity.RequestModel{Method: http.MethodGet, Headers: tt.headers}, entity.CachePolicy{BypassCookies: tt.cookies})
+			if got != tt.want {
+				t.Fatalf("ShouldBypass() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package domainservice
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+)
+
+// retryBudgetReserveSeconds caps the balance at this many seconds of minimum retries (and
+// at least retryBudgetMinCap), so a long healthy period cannot bank an unlimited burst.
+const (
+	retryBudgetReserveSeconds = 10
+	retryBudgetMinCap         = 100
+)
+
+// RetryBudget limits retries to a share of the request rate across all keys, so that
+// retries cannot multiply the load on an origin that is already failing. Every request
+// earns ratio retries and minPerSecond more are earned over time for quiet periods.
+type RetryBudget struct {
+	ratio        float64
+	minPerSecond float64
+	maxBalance   float64
+
+	mu      sync.Mutex
+	balance float64
+	last    int64
+}
+
+func NewRetryBudget(ratio float64, minPerSecond float64) contract.IRetryBudget {
+	maxBalance := max(minPerSecond*retryBudgetReserveSeconds, retryBudgetMinCap)
+	// start with a second's worth so failures right after startup can be retried
+	return &RetryBudget{ratio: ratio, minPerSecond: minPerSecond, maxBalance: maxBalance, balance: minPerSecond}
+}
+
+func (b *RetryBudget) Deposit(now time.Time) {
+	b.mu.Lock()
+	b.refill(now)
+	b.balance = min(b.balance+b.ratio, b.maxBalance)
+	b.mu.Unlock()
+}
+
+func (b *RetryBudget) Withdraw(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	if b.balance < 1 {
+		return false
+	}
+	b.balance--
+	return true
+}
+
+// refill credits the minimum retry rate for the time since the last call. The caller must
+// hold the lock.
+func (b *RetryBudget) refill(now time.Time) {
+	nowNano := now.UnixNano()
+	if b.last != 0 && nowNano > b.last {
+		elapsed := time.Duration(nowNano - b.last).Seconds()
+		b.balance = min(b.balance+elapsed*b.minPerSecond, b.maxBalance)
+	}
+	b.last = nowNano
+}
@@ -0,0 +1,62 @@
+package domainservice
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryBudget(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	tests := []struct {
+		name         string
+		ratio        float64
+		minPerSecond float64
+		deposits     int
+		// elapsed passes between the deposits, the first of which starts the clock, and the
+		// withdrawals
+		elapsed time.Duration
+		want    int
+	}{
+		{name: "starts with a second of the minimum rate", minPerSecond: 3, want: 3},
+		{name: "empty without a minimum rate or requests", want: 0},
+		{name: "requests earn their ratio", ratio: 0.5, deposits: 5, want: 2},
+		{name: "fractional balance is not withdrawn", ratio: 0.1, deposits: 9, want: 0},
+		{name: "quiet periods earn the minimum rate", minPerSecond: 2, deposits: 1, elapsed: 1500 * time.Millisecond, want: 5},
+		{name: "balance is capped at the minimum cap", ratio: 1, deposits: 500, want: retryBudgetMinCap},
+		{name: "balance is capped at the reserve of the minimum rate", minPerSecond: 20, deposits: 1, elapsed: time.Hour, want: 20 * retryBudgetReserveSeconds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewRetryBudget(tt.ratio, tt.minPerSecond)
+			for i := 0; i < tt.deposits; i++ {
+				b.Deposit(start)
+			}
+			now := start.Add(tt.elapsed)
+			got := 0
+			for b.Withdraw(now) {
+				got++
+				if got > 10000 {
+					t.Fatal("budget never ran out")
+				}
+			}
+			if got != tt.want {
+				t.Fatalf("withdrawals = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryBudgetIgnoresClockGoingBackwards(t *testing.T) {
+	b := NewRetryBudget(0, 1)
+	now := time.Unix(1000, 0)
+	if !b.Withdraw(now) {
+		t.Fatal("initial balance was not withdrawn")
+	}
+	if b.Withdraw(now.Add(-time.Hour)) {
+		t.Fatal("a clock step backwards earned retries")
+	}
+	if !b.Withdraw(now.Add(time.Second)) {
+		t.Fatal("a second later the minimum rate was not earned")
+	}
+}
@@ -0,0 +1,97 @@
+package domainservice
+
+import (
+	"hash/maphash"
+	"sync"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+const (
+	// limiterShards spreads buckets over independently locked maps to limit contention.
+	limiterShards = 64
+	// limiterSweepInterval is how often a shard drops idle buckets.
+	limiterSweepInterval = time.Minute
+)
+
+// TokenBucketLimiter keeps one token bucket per key. A bucket that has refilled completely
+// behaves exactly like a new one, so idle buckets are dropped once full and memory only
+// grows with the number of recently active clients.
+type TokenBucketLimiter struct {
+	seed   maphash.Seed
+	shards [limiterShards]limiterShard
+}
+
+// limiterShard maps keys to the moment, in unix nanoseconds, their bucket will be full
+// again. Storing that instead of a token count keeps a bucket to one integer: each request
+// pushes it one interval further, and it may run at most one burst ahead of now.
+type limiterShard struct {
+	mu        sync.Mutex
+	buckets   map[string]int64
+	lastSweep int64
+}
+
+func NewTokenBucketLimiter() contract.IRateLimiter {
+	l := &TokenBucketLimiter{seed: maphash.MakeSeed()}
+	for i := range l.shards {
+		l.shards[i].buckets = make(map[string]int64)
+	}
+	return l
+}
+
+func (l *TokenBucketLimiter) Allow(key string, limit valueobject.RateLimit, now time.Time) entity.RateLimitDecision {
+	interval := int64(limit.Interval())
+	capacity := int64(max(limit.Burst, 1)) * interval
+	nowNano := now.UnixNano()
+
+	shard := &l.shards[maphash.String(l.seed, key)%limiterShards]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if nowNano-shard.lastSweep >= int64(limiterSweepInterval) {
+		shard.sweep(nowNano)
+	}
+
+	fullAt := max(shard.buckets[key], nowNano)
+	// after this request the bucket would hold (capacity - debt) / interval tokens
+	next := fullAt + interval
+	debt := next - nowNano
+	if debt > capacity {
+		return entity.RateLimitDecision{
+			Limit:      limit.Limit,
+			Remaining:  0,
+			Reset:      time.Duration(fullAt - nowNano),
+			RetryAfter: time.Duration(debt - capacity),
+		}
+	}
+	shard.buckets[key] = next
+	return entity.RateLimitDecision{
+		Allowed:   true,
+		Limit:     limit.Limit,
+		Remaining: int((capacity - debt) / interval),
+		Reset:     time.Duration(debt),
+	}
+}
+
+func (l *TokenBucketLimiter) Len() int {
+	n := 0
+	for i := range l.shards {
+		shard := &l.shards[i]
+		shard.mu.Lock()
+		n += len(shard.buckets)
+		shard.mu.Unlock()
+	}
+	return n
+}
+
+// sweep drops buckets that have refilled completely. The caller must hold the lock.
+func (s *limiterShard) sweep(now int64) {
+	for key, fullAt := range s.buckets {
+		if fullAt <= now {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
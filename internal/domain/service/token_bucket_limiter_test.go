@@ -0,0 +1,134 @@
+package domainservice
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+func TestTokenBucketLimiterAllow(t *testing.T) {
+	start := time.Unix(1000, 0)
+	limit := valueobject.RateLimit{Limit: 10, Window: time.Second, Burst: 3}
+
+	tests := []struct {
+		name string
+		// offsets are the request times relative to start
+		offsets       []time.Duration
+		wantAllowed   []bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{
+			name:          "burst is allowed at once",
+			offsets:       []time.Duration{0, 0, 0},
+			wantAllowed:   []bool{true, true, true},
+			wantRemaining: 0,
+		},
+		{
+			name:        "request beyond the burst is rejected until a token is earned",
+			offsets:     []time.Duration{0, 0, 0, 0},
+			wantAllowed: []bool{true, true, true, false},
+			wantRetry:   100 * time.Millisecond,
+		},
+		{
+			name:          "one interval earns one token",
+			offsets:       []time.Duration{0, 0, 0, 100 * time.Millisecond},
+			wantAllowed:   []bool{true, true, true, true},
+			wantRemaining: 0,
+		},
+		{
+			name:          "partial interval does not earn a token",
+			offsets:       []time.Duration{0, 0, 0, 50 * time.Millisecond},
+			wantAllowed:   []bool{true, true, true, false},
+			wantRetry:     50 * time.Millisecond,
+			wantRemaining: 0,
+		},
+		{
+			name:          "idle bucket refills to the burst and no further",
+			offsets:       []time.Duration{0, time.Hour},
+			wantAllowed:   []bool{true, true},
+			wantRemaining: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewTokenBucketLimiter()
+			for i, offset := range tt.offsets {
+				got := l.Allow("client", limit, start.Add(offset))
+				if got.Allowed != tt.wantAllowed[i] {
+					t.Fatalf("request %d allowed = %v, want %v", i, got.Allowed, tt.wantAllowed[i])
+				}
+				if got.Limit != limit.Limit {
+					t.Errorf("request %d limit = %d, want %d", i, got.Limit, limit.Limit)
+				}
+				if i < len(tt.offsets)-1 {
+					continue
+				}
+				if got.Remaining != tt.wantRemaining {
+					t.Errorf("remaining = %d, want %d", got.Remaining, tt.wantRemaining)
+				}
+				if got.RetryAfter != tt.wantRetry {
+					t.Errorf("retry after = %s, want %s", got.RetryAfter, tt.wantRetry)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenBucketLimiterReset(t *testing.T) {
+	l := NewTokenBucketLimiter()
+	now := time.Unix(1000, 0)
+	limit := valueobject.RateLimit{Limit: 2, Window: time.Second, Burst: 2}
+
+	if got := l.Allow("client", limit, now); got.Reset != 500*time.Millisecond || got.Remaining != 1 {
+		t.Fatalf("first request = remaining %d reset %s, want 1 and 500ms", got.Remaining, got.Reset)
+	}
+	if got := l.Allow("client", limit, now); got.Reset != time.Second || got.Remaining != 0 {
+		t.Fatalf("second request = remaining %d reset %s, want 0 and 1s", got.Remaining, got.Reset)
+	}
+	got := l.Allow("client", limit, now)
+	if got.Allowed || got.Reset != time.Second || got.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("third request = %+v, want rejected with reset 1s and retry after 500ms", got)
+	}
+}
+
+func TestTokenBucketLimiterKeysAreIndependent(t *testing.T) {
+	l := NewTokenBucketLimiter()
+	now := time.Unix(1000, 0)
+	limit := valueobject.RateLimit{Limit: 1, Window: time.Second, Burst: 1}
+
+	if !l.Allow("a", limit, now).Allowed {
+		t.Fatal("first request for a was rejected")
+	}
+	if l.Allow("a", limit, now).Allowed {
+		t.Fatal("second request for a was allowed")
+	}
+	if !l.Allow("b", limit, now).Allowed {
+		t.Fatal("request for b was limited by a's bucket")
+	}
+}
+
+func TestTokenBucketLimiterSweepsFullBuckets(t *testing.T) {
+	l := NewTokenBucketLimiter()
+	now := time.Unix(1000, 0)
+	limit := valueobject.RateLimit{Limit: 10, Window: time.Second, Burst: 10}
+
+	// enough keys that every shard holds some of them
+	const keys = 2000
+	for i := 0; i < keys; i++ {
+		l.Allow("old"+strconv.Itoa(i), limit, now)
+	}
+	if got := l.Len(); got != keys {
+		t.Fatalf("Len() = %d, want %d", got, keys)
+	}
+	// the old buckets have refilled by the next sweep, which every shard runs on its next request
+	later := now.Add(limiterSweepInterval)
+	for i := 0; i < keys; i++ {
+		l.Allow("new"+strconv.Itoa(i), limit, later)
+	}
+	if got := l.Len(); got != keys {
+		t.Fatalf("Len() after the sweep = %d, want only the %d new buckets", got, keys)
+	}
+}
@@ -1,6 +1,22 @@
 package valueobject
 
 type CacheKey struct {
-	Method string
+	Method        string
 	NormalizedURL string
-}
\ No newline at end of file
+	// Namespace separates keys of different origins sharing one cache.
+	Namespace string
+	// Variant holds the request headers and cookies selected into the key.
+	Variant string
+}
+
+// String renders the key as "[namespace/]METHOD:URL[#variant]".
+func (k CacheKey) String() string {
+	s := k.Method + ":" + k.NormalizedURL
+	if k.Namespace != "" {
+		s = k.Namespace + "/" + s
+	}
+	if k.Variant != "" {
+		s += "#" + k.Variant
+	}
+	return s
+}
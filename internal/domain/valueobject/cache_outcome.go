@@ -0,0 +1,13 @@
+package valueobject
+
+// CacheOutcome summarises how the cache took part in answering a request.
+type CacheOutcome string
+
+const (
+	CacheOutcomeNone        CacheOutcome = "none"
+	CacheOutcomeHit         CacheOutcome = "hit"
+	CacheOutcomeMiss        CacheOutcome = "miss"
+	CacheOutcomeStale       CacheOutcome = "stale"
+	CacheOutcomeBypass      CacheOutcome = "bypass"
+	CacheOutcomeRevalidated CacheOutcome = "revalidated"
+)
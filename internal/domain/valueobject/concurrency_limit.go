@@ -0,0 +1,17 @@
+package valueobject
+
+import "time"
+
+// ConcurrencyLimit bounds the requests in flight to one upstream. Requests over the limit
+// wait in a FIFO queue of up to MaxQueue requests for at most QueueTimeout.
+type ConcurrencyLimit struct {
+	Max          int
+	MaxQueue     int
+	QueueTimeout time.Duration
+	// Adaptive moves the limit between Min and Max: it grows by one per limit's worth of
+	// fast responses and shrinks by a tenth on every failure or response slower than
+	// LatencyThreshold (AIMD).
+	Adaptive         bool
+	Min              int
+	LatencyThreshold time.Duration
+}
@@ -0,0 +1,15 @@
+package valueobject
+
+// EvictionReason tells why an item left the cache.
+type EvictionReason string
+
+const (
+	// EvictionCapacity is an eviction made to stay within max_cost.
+	EvictionCapacity EvictionReason = "capacity"
+	// EvictionExpired is an item removed once its TTL passed.
+	EvictionExpired EvictionReason = "expired"
+	// EvictionRejected is a new item the admission policy refused to store.
+	EvictionRejected EvictionReason = "rejected"
+	// EvictionPurged is an item removed through the admin API.
+	EvictionPurged EvictionReason = "purged"
+)
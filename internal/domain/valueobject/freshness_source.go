@@ -0,0 +1,13 @@
+package valueobject
+
+// FreshnessSource records where a cached response's freshness lifetime came from.
+type FreshnessSource string
+
+const (
+	FreshnessNone      FreshnessSource = ""
+	FreshnessExplicit  FreshnessSource = "explicit"
+	FreshnessHeuristic FreshnessSource = "heuristic"
+	FreshnessDefault   FreshnessSource = "default"
+	FreshnessStatus    FreshnessSource = "status"
+	FreshnessAdaptive  FreshnessSource = "adaptive"
+)
@@ -0,0 +1,14 @@
+package valueobject
+
+// HotKeyDimension is a measure hot keys and clients can be ranked by.
+type HotKeyDimension string
+
+const (
+	HotKeyByRequests   HotKeyDimension = "requests"
+	HotKeyByMisses     HotKeyDimension = "misses"
+	HotKeyByBytes      HotKeyDimension = "bytes"
+	HotKeyByOriginTime HotKeyDimension = "origin_time"
+)
+
+// HotKeyDimensions lists every dimension in display order.
+var HotKeyDimensions = []HotKeyDimension{HotKeyByRequests, HotKeyByMisses, HotKeyByBytes, HotKeyByOriginTime}
@@ -0,0 +1,8 @@
+package valueobject
+
+import "time"
+
+// Millis renders a duration as fractional milliseconds for log fields.
+func Millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,34 @@
+package valueobject
+
+import (
+	"strings"
+	"time"
+)
+
+// OriginTimeouts bound the phases of an origin request. Zero disables a timeout.
+type OriginTimeouts struct {
+	Dial           time.Duration
+	TLSHandshake   time.Duration
+	ResponseHeader time.Duration
+	// Total covers one attempt from dialing to reading the last body byte.
+	Total       time.Duration
+	HealthCheck time.Duration
+	// Routes override Total for proxied paths starting with PathPrefix.
+	Routes []RouteTimeout
+}
+
+type RouteTimeout struct {
+	PathPrefix string
+	Total      time.Duration
+}
+
+// TotalFor returns the total timeout for path; the longest matching route prefix wins.
+func (t OriginTimeouts) TotalFor(path string) time.Duration {
+	total, matched := t.Total, -1
+	for _, route := range t.Routes {
+		if len(route.PathPrefix) > matched && strings.HasPrefix(path, route.PathPrefix) {
+			total, matched = route.Total, len(route.PathPrefix)
+		}
+	}
+	return total
+}
@@ -0,0 +1,50 @@
+package valueobject
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOriginTimeoutsTotalFor(t *testing.T) {
+	timeouts := OriginTimeouts{
+		Total: 30 * time.Second,
+		Routes: []RouteTimeout{
+			{PathPrefix: "/reports", Total: 2 * time.Minute},
+			{PathPrefix: "/reports/live", Total: 5 * time.Second},
+			{PathPrefix: "/stream", Total: 0},
+		},
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want time.Duration
+	}{
+		{name: "no route matches", path: "/items/1", want: 30 * time.Second},
+		{name: "prefix match", path: "/reports/2024", want: 2 * time.Minute},
+		{name: "longest prefix wins", path: "/reports/live/today", want: 5 * time.Second},
+		{name: "route can disable the timeout", path: "/stream/events", want: 0},
+		{name: "prefixes are not path segments", path: "/reportsarchive", want: 2 * time.Minute},
+		{name: "empty path", path: "", want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := timeouts.TotalFor(tt.path); got != tt.want {
+				t.Fatalf("TotalFor(%q) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOriginTimeoutsTotalForOrderIndependent(t *testing.T) {
+	timeouts := OriginTimeouts{
+		Total: time.Second,
+		Routes: []RouteTimeout{
+			{PathPrefix: "/a/b", Total: 3 * time.Second},
+			{PathPrefix: "/a", Total: 2 * time.Second},
+		},
+	}
+	if got := timeouts.TotalFor("/a/b/c"); got != 3*time.Second {
+		t.Fatalf("TotalFor = %s, want the longer prefix's 3s", got)
+	}
+}
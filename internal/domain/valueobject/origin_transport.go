@@ -0,0 +1,18 @@
+package valueobject
+
+import "time"
+
+// OriginTransport configures the connection pool and protocols used towards an upstream.
+type OriginTransport struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps dialing, in-use and idle connections together; zero is unlimited.
+	MaxConnsPerHost int
+	IdleConnTimeout time.Duration
+	// HTTP2 negotiates HTTP/2 over TLS. H2C speaks HTTP/2 without TLS to http:// upstreams,
+	// which must then support it with prior knowledge.
+	HTTP2 bool
+	H2C   bool
+	// DisableKeepAlives opens a new connection for every request.
+	DisableKeepAlives bool
+}
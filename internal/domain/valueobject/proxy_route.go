@@ -0,0 +1,5 @@
+package valueobject
+
+// ProxyRoutePrefix is where the proxy is mounted; the rest of the request path is forwarded
+// to the origin.
+const ProxyRoutePrefix = "/api/v1/proxy"
@@ -0,0 +1,28 @@
+package valueobject
+
+import "time"
+
+// RateLimitKey selects what a rate limit bucket is keyed on.
+type RateLimitKey string
+
+const (
+	// RateLimitByIP gives every client IP its own bucket.
+	RateLimitByIP RateLimitKey = "ip"
+	// RateLimitByAPIKey gives every known API key its own bucket; requests without one are
+	// keyed on their IP.
+	RateLimitByAPIKey RateLimitKey = "api_key"
+	// RateLimitByRoute shares one bucket between all clients of a route.
+	RateLimitByRoute RateLimitKey = "route"
+)
+
+// RateLimit allows Limit requests per Window on average and bursts of up to Burst requests.
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+	Burst  int
+}
+
+// Interval is the time it takes to earn one request.
+func (r RateLimit) Interval() time.Duration {
+	return r.Window / time.Duration(r.Limit)
+}
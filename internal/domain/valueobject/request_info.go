@@ -0,0 +1,29 @@
+package valueobject
+
+import "context"
+
+// RequestIDHeader carries the request ID between clients, the proxy and the origin.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestInfo identifies the inbound request a context belongs to so that log lines and
+// spans emitted while serving it can be correlated.
+type RequestInfo struct {
+	RequestID string
+	ClientIP  string
+	Route     string
+}
+
+type requestInfoKey struct{}
+
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext returns the request info stored in ctx, if any.
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	if ctx == nil {
+		return RequestInfo{}, false
+	}
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
@@ -0,0 +1,38 @@
+package valueobject
+
+import (
+	"net/http"
+	"slices"
+	"time"
+)
+
+// RetryPolicy controls how failed origin fetches of idempotent requests are repeated.
+type RetryPolicy struct {
+	// MaxAttempts counts the first try; one disables retries.
+	MaxAttempts int
+	// BaseDelay doubles with every retry up to MaxDelay; the actual wait is drawn uniformly
+	// below it (full jitter).
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Statuses are origin response codes worth another attempt, e.g. 502, 503 and 504.
+	Statuses []int
+	// HedgeAfter sends a second, concurrent attempt when the first has not answered in time;
+	// zero disables hedging.
+	HedgeAfter time.Duration
+}
+
+// RetriesStatus reports whether an origin response with status should be retried.
+func (p RetryPolicy) RetriesStatus(status int) bool {
+	return slices.Contains(p.Statuses, status)
+}
+
+// Retryable reports whether requests with method may be sent more than once. Only methods
+// RFC 9110 defines as idempotent qualify.
+func (p RetryPolicy) Retryable(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,11 @@
+package valueobject
+
+// SetCookieMode controls how responses carrying Set-Cookie are treated by the cache.
+type SetCookieMode string
+
+const (
+	// SetCookieSkip never stores responses that set cookies.
+	SetCookieSkip SetCookieMode = "skip"
+	// SetCookieStrip stores the response with its Set-Cookie headers removed.
+	SetCookieStrip SetCookieMode = "strip"
+)
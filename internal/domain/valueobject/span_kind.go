@@ -0,0 +1,10 @@
+package valueobject
+
+// SpanKind describes a traced operation's role, mirroring the OpenTelemetry span kinds.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+	SpanKindClient
+)
@@ -8,3 +8,14 @@ type TTL struct {
 	Min      time.Duration
 	Max      time.Duration
 }
+
+// Clamp bounds d to [Min, Max]; a zero bound is treated as unset.
+func (t TTL) Clamp(d time.Duration) time.Duration {
+	if t.Min > 0 && d < t.Min {
+		return t.Min
+	}
+	if t.Max > 0 && d > t.Max {
+		return t.Max
+	}
+	return d
+}
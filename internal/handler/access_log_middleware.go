@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// upstreamTimingContextKey is where the proxy handler leaves the origin timing for the
+// access log middleware.
+const upstreamTimingContextKey = "upstream_timing"
+
+// AccessLogMiddleware writes one access log entry per request once it has been served.
+type AccessLogMiddleware struct {
+	accessLogger contract.IAccessLogger
+	timeService  contract.ITimeService
+}
+
+func NewAccessLogMiddleware(accessLogger contract.IAccessLogger, timeService contract.ITimeService) *AccessLogMiddleware {
+	return &AccessLogMiddleware{accessLogger: accessLogger, timeService: timeService}
+}
+
+func (m *AccessLogMiddleware) Handle(c *gin.Context) {
+	received := m.timeService.Now()
+	start := m.timeService.Monotonic()
+	c.Next()
+
+	entry := entity.AccessLogEntry{
+		Time:        received,
+		ClientIP:    c.ClientIP(),
+		Method:      c.Request.Method,
+		URI:         c.Request.RequestURI,
+		Proto:       c.Request.Proto,
+		Route:       c.FullPath(),
+		Status:      c.Writer.Status(),
+		Bytes:       c.Writer.Size(),
+		Referer:     c.Request.Referer(),
+		UserAgent:   c.Request.UserAgent(),
+		Duration:    m.timeService.Since(start),
+		CacheStatus: valueobject.CacheOutcomeNone,
+	}
+	if info, ok := valueobject.RequestInfoFromContext(c.Request.Context()); ok {
+		entry.RequestID = info.RequestID
+	}
+	if value, ok := c.Get(cacheOutcomeContextKey); ok {
+		if outcome, ok := value.(valueobject.CacheOutcome); ok && outcome != "" {
+			entry.CacheStatus = outcome
+		}
+	}
+	if value, ok := c.Get(upstreamTimingContextKey); ok {
+		if timing, ok := value.(entity.UpstreamTiming); ok {
+			entry.UpstreamAddr = timing.Addr
+			entry.UpstreamTime = timing.Total
+			entry.UpstreamBytes = timing.Bytes
+		}
+	}
+	m.accessLogger.Log(entry)
+}
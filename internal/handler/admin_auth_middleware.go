@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+const (
+	adminActorContextKey      = "admin_actor"
+	adminDenyReasonContextKey = "admin_deny_reason"
+)
+
+// AdminAuthMiddleware guards the admin and metrics routes with an IP allowlist and bearer
+// tokens and writes admin actions to the audit log. Client certificates are verified by the
+// admin listener's TLS configuration before requests get here.
+type AdminAuthMiddleware struct {
+	tokens      map[string]string
+	networks    []*net.IPNet
+	auditLogger contract.IAuditLogger
+	timeService contract.ITimeService
+}
+
+func NewAdminAuthMiddleware(cfg config.AdminConfig, auditLogger contract.IAuditLogger, timeService contract.ITimeService) (*AdminAuthMiddleware, error) {
+	networks := make([]*net.IPNet, 0, len(cfg.AllowedCIDRs))
+	for _, cidr := range cfg.AllowedCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid admin.allowed_cidrs entry %q: %w", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return &AdminAuthMiddleware{
+		tokens:      cfg.Tokens,
+		networks:    networks,
+		auditLogger: auditLogger,
+		timeService: timeService,
+	}, nil
+}
+
+// Allowlist records the caller and rejects addresses outside admin.allowed_cidrs. It guards
+// the dashboard's static files, which carry no data of their own.
+func (m *AdminAuthMiddleware) Allowlist(c *gin.Context) {
+	if m.checkAddress(c) {
+		c.Next()
+	}
+}
+
+// Authenticate rejects callers outside the allowlist or without a valid bearer token and
+// records who the caller is for the audit log.
+func (m *AdminAuthMiddleware) Authenticate(c *gin.Context) {
+	if !m.checkAddress(c) {
+		return
+	}
+	if len(m.tokens) > 0 {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="revprox-admin"`)
+			m.deny(c, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+		name, found := m.tokenOwner(token)
+		if !found {
+			c.Header("WWW-Authenticate", `Bearer realm="revprox-admin", error="invalid_token"`)
+			m.deny(c, http.StatusUnauthorized, "invalid bearer token")
+			return
+		}
+		c.Set(adminActorContextKey, "token:"+name)
+	}
+	c.Next()
+}
+
+// Audit writes one audit event per admin call once it has been handled, including calls
+// that Authenticate rejected.
+func (m *AdminAuthMiddleware) Audit(c *gin.Context) {
+	received := m.timeService.Now()
+	c.Next()
+
+	event := entity.AuditEvent{
+		Time:       received,
+		Actor:      c.GetString(adminActorContextKey),
+		RemoteAddr: c.Request.RemoteAddr,
+		Method:     c.Request.Method,
+		Path:       c.Request.URL.Path,
+		Query:      c.Request.URL.RawQuery,
+		Status:     c.Writer.Status(),
+		DenyReason: c.GetString(adminDenyReasonContextKey),
+	}
+	event.Allowed = event.DenyReason == ""
+	if info, ok := valueobject.RequestInfoFromContext(c.Request.Context()); ok {
+		event.RequestID = info.RequestID
+	}
+	m.auditLogger.Record(c.Request.Context(), event)
+}
+
+// checkAddress sets the actor from the connection and aborts callers outside the allowlist.
+func (m *AdminAuthMiddleware) checkAddress(c *gin.Context) bool {
+	// Unix socket peers have no IP; access to them is governed by the socket's file mode.
+	ip := net.ParseIP(c.RemoteIP())
+	actor := "unix-socket"
+	if ip != nil {
+		actor = "ip:" + ip.String()
+	}
+	if tls := c.Request.TLS; tls != nil && len(tls.PeerCertificates) > 0 {
+		actor = "cert:" + tls.PeerCertificates[0].Subject.CommonName
+	}
+	c.Set(adminActorContextKey, actor)
+
+	if ip != nil && len(m.networks) > 0 && !m.allowed(ip) {
+		m.deny(c, http.StatusForbidden, "address not in admin.allowed_cidrs")
+		return false
+	}
+	return true
+}
+
+func (m *AdminAuthMiddleware) allowed(ip net.IP) bool {
+	for _, network := range m.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenOwner compares token against every configured token in constant time.
+func (m *AdminAuthMiddleware) tokenOwner(token string) (string, bool) {
+	owner, found := "", false
+	for name, candidate := range m.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+			owner, found = name, true
+		}
+	}
+	return owner, found
+}
+
+func (m *AdminAuthMiddleware) deny(c *gin.Context, status int, reason string) {
+	c.Set(adminDenyReasonContextKey, reason)
+	c.AbortWithStatusJSON(status, gin.H{"error": reason})
+}
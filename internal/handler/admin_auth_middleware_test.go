@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+// recordingAuditLogger keeps the audit events it is given.
+type recordingAuditLogger struct {
+	events []entity.AuditEvent
+}
+
+func (l *recordingAuditLogger) Record(ctx context.Context, event entity.AuditEvent) {
+	l.events = append(l.events, event)
+}
+
+func (l *recordingAuditLogger) Close() error {
+	return nil
+}
+
+// stoppedClock always returns now.
+type stoppedClock struct{ now time.Time }
+
+func (s stoppedClock) Now() time.Time                      { return s.now }
+func (s stoppedClock) NowUnix() int64                      { return s.now.Unix() }
+func (s stoppedClock) Monotonic() time.Time                { return s.now }
+func (s stoppedClock) Since(start time.Time) time.Duration { return s.now.Sub(start) }
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	cfg := config.AdminConfig{
+		AllowedCIDRs: []string{"10.0.0.0/8"},
+		Tokens:       map[string]string{"ops": "s3cret-token"},
+	}
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		authorization string
+		wantStatus    int
+		wantChallenge string
+		wantActor     string
+		wantDeny      string
+	}{
+		{
+			name:          "allowed address with the right token",
+			remoteAddr:    "10.1.2.3:51000",
+			authorization: "Bearer s3cret-token",
+			wantStatus:    http.StatusOK,
+			wantActor:     "token:ops",
+		},
+		{
+			name:          "address outside the allowlist",
+			remoteAddr:    "203.0.113.7:51000",
+			authorization: "Bearer s3cret-token",
+			wantStatus:    http.StatusForbidden,
+			wantActor:     "ip:203.0.113.7",
+			wantDeny:      "address not in admin.allowed_cidrs",
+		},
+		{
+			name:          "missing token",
+			remoteAddr:    "10.1.2.3:51000",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="revprox-admin"`,
+			wantActor:     "ip:10.1.2.3",
+			wantDeny:      "missing bearer token",
+		},
+		{
+			name:          "wrong token",
+			remoteAddr:    "10.1.2.3:51000",
+			authorization: "Bearer s3cret-tokem",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="revprox-admin", error="invalid_token"`,
+			wantActor:     "ip:10.1.2.3",
+			wantDeny:      "invalid bearer token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditLogger := &recordingAuditLogger{}
+			m, err := NewAdminAuthMiddleware(cfg, auditLogger, stoppedClock{now})
+			if err != nil {
+				t.Fatal(err)
+			}
+			engine := gin.New()
+			if err := engine.SetTrustedProxies(nil); err != nil {
+				t.Fatal(err)
+			}
+			admin := engine.Group("/admin", m.Audit, m.Authenticate)
+			admin.GET("/entries", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/entries?limit=5", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("WWW-Authenticate"); got != tt.wantChallenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.wantChallenge)
+			}
+			if len(auditLogger.events) != 1 {
+				t.Fatalf("audit events = %d, want 1", len(auditLogger.events))
+			}
+			want := entity.AuditEvent{
+				Time:       now,
+				Actor:      tt.wantActor,
+				RemoteAddr: tt.remoteAddr,
+				Method:     http.MethodGet,
+				Path:       "/admin/entries",
+				Query:      "limit=5",
+				Status:     tt.wantStatus,
+				Allowed:    tt.wantDeny == "",
+				DenyReason: tt.wantDeny,
+			}
+			if got := auditLogger.events[0]; got != want {
+				t.Fatalf("audit event = %+v\nwant          %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+const (
+	// defaultHotKeyLimit is how many entries the hot key endpoints return without ?limit.
+	defaultHotKeyLimit = 20
+	// defaultEntryLimit is how many cache entries the entries endpoint returns without ?limit.
+	defaultEntryLimit = 100
+)
+
+// AdminHandler serves the operational endpoints under /api/v1/admin.
+type AdminHandler struct {
+	statsUseCase   contract.IStatsUseCase
+	explainUseCase contract.IExplainUseCase
+	inspectUseCase contract.ICacheInspectUseCase
+	purgeUseCase   contract.IPurgeUseCase
+	settings       map[string]any
+	logger         contract.ILogger
+}
+
+func NewAdminHandler(statsUC contract.IStatsUseCase, explainUC contract.IExplainUseCase, inspectUC contract.ICacheInspectUseCase, purgeUC contract.IPurgeUseCase, cfg config.Config, logger contract.ILogger) *AdminHandler {
+	return &AdminHandler{
+		statsUseCase:   statsUC,
+		explainUseCase: explainUC,
+		inspectUseCase: inspectUC,
+		purgeUseCase:   purgeUC,
+		settings:       cfg.Settings(),
+		logger:         logger,
+	}
+}
+
+// Stats returns cache occupancy and hit, error and latency figures per sliding window.
+func (h *AdminHandler) Stats(c *gin.Context) {
+	report := h.statsUseCase.Stats(c.Request.Context())
+
+	windows := gin.H{}
+	for _, m := range report.Windows {
+		windows[windowLabel(m.Window)] = windowJSON(m)
+	}
+	usedRatio := 0.0
+	if report.Cache.MaxBytes > 0 {
+		usedRatio = float64(report.Cache.Bytes) / float64(report.Cache.MaxBytes)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"generated_at": report.GeneratedAt.UTC().Format(time.RFC3339),
+		"cache": gin.H{
+			"entries":    report.Cache.Entries,
+			"bytes":      report.Cache.Bytes,
+			"max_bytes":  report.Cache.MaxBytes,
+			"used_ratio": usedRatio,
+		},
+		"windows": windows,
+	})
+}
+
+// HotKeys ranks cache keys by ?by=requests|misses|bytes|origin_time.
+func (h *AdminHandler) HotKeys(c *gin.Context) {
+	h.hotKeys(c, h.statsUseCase.HotKeys)
+}
+
+// HotClients ranks client IPs the same way as HotKeys.
+func (h *AdminHandler) HotClients(c *gin.Context) {
+	h.hotKeys(c, h.statsUseCase.HotClients)
+}
+
+func (h *AdminHandler) hotKeys(c *gin.Context, top func(context.Context, valueobject.HotKeyDimension, int) []entity.HotKey) {
+	by := valueobject.HotKeyDimension(c.DefaultQuery("by", string(valueobject.HotKeyByRequests)))
+	if !slices.Contains(valueobject.HotKeyDimensions, by) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "by must be one of requests, misses, bytes, origin_time"})
+		return
+	}
+	limit, ok := queryLimit(c, defaultHotKeyLimit)
+	if !ok {
+		return
+	}
+
+	hot := top(c.Request.Context(), by, limit)
+	items := make([]gin.H, 0, len(hot))
+	for _, k := range hot {
+		items = append(items, gin.H{
+			"key":            k.Key,
+			"requests":       k.Requests,
+			"misses":         k.Misses,
+			"bytes":          k.Bytes,
+			"origin_time_ms": millis(k.OriginTime),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"by": by, "items": items})
+}
+
+// Entries pages through cached responses ordered by key. Filters: ?prefix= and ?regex= on
+// the key string; paging: ?limit= and ?cursor= (the next_cursor of the previous page);
+// body=true includes response bodies.
+func (h *AdminHandler) Entries(c *gin.Context) {
+	limit, ok := queryLimit(c, defaultEntryLimit)
+	if !ok {
+		return
+	}
+	query := entity.CacheEntryQuery{
+		Prefix:      c.Query("prefix"),
+		After:       c.Query("cursor"),
+		Limit:       limit,
+		IncludeBody: c.Query("body") == "true",
+	}
+	if raw := c.Query("regex"); raw != "" {
+		pattern, err := regexp.Compile(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid regex", "details": err.Error()})
+			return
+		}
+		query.Pattern = pattern
+	}
+	page := h.inspectUseCase.List(c.Request.Context(), query)
+	items := make([]gin.H, 0, len(page.Entries))
+	for _, e := range page.Entries {
+		items = append(items, entryJSON(e))
+	}
+	c.JSON(http.StatusOK, gin.H{"total": page.Total, "next_cursor": page.NextCursor, "items": items})
+}
+
+// Entry returns the response cached under the exact ?key=, with its body when body=true.
+func (h *AdminHandler) Entry(c *gin.Context) {
+	key := c.Query("key")
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "key is required"})
+		return
+	}
+	info, found := h.inspectUseCase.Lookup(c.Request.Context(), key, c.Query("body") == "true")
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "key is not cached", "key": key})
+		return
+	}
+	c.JSON(http.StatusOK, entryJSON(info))
+}
+
+// Purge removes the entry with the exact ?key= or every entry whose key starts with ?prefix=.
+func (h *AdminHandler) Purge(c *gin.Context) {
+	key, prefix := c.Query("key"), c.Query("prefix")
+	if (key == "") == (prefix == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of key or prefix is required"})
+		return
+	}
+	var purged int
+	var err error
+	if key != "" {
+		purged, err = h.purgeUseCase.Purge(c.Request.Context(), key)
+	} else {
+		purged, err = h.purgeUseCase.PurgePrefix(c.Request.Context(), prefix)
+	}
+	if err != nil {
+		h.logger.Error(c.Request.Context(), "Cache purge failed", valueobject.LogField{Key: "error", Value: err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "purge failed", "purged": purged})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"purged": purged})
+}
+
+// Config returns the effective configuration with secrets redacted.
+func (h *AdminHandler) Config(c *gin.Context) {
+	c.JSON(http.StatusOK, h.settings)
+}
+
+// Explain dry-runs ?url= (a path and query as sent to /proxy) through key normalization,
+// cache lookup and the cache policy. Optional parameters: method (default GET), repeated
+// header=Name:value, and fetch=false to skip asking the origin when nothing is cached.
+func (h *AdminHandler) Explain(c *gin.Context) {
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid url", "details": err.Error()})
+		return
+	}
+	headers := http.Header{}
+	for _, h := range c.QueryArray("header") {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "header must look like Name:value", "header": h})
+			return
+		}
+		headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	method := strings.ToUpper(c.DefaultQuery("method", http.MethodGet))
+	fetch := c.DefaultQuery("fetch", "true") != "false" && (method == http.MethodGet || method == http.MethodHead)
+
+	req := entity.RequestModel{
+		Method:   method,
+		URL:      &url.URL{Path: target.Path, RawQuery: target.RawQuery},
+		Headers:  headers,
+		ClientIP: c.ClientIP(),
+	}
+	e := h.explainUseCase.Explain(c.Request.Context(), req, fetch)
+
+	body := gin.H{
+		"key": gin.H{
+			"method":         e.Key.Method,
+			"normalized_url": e.Key.NormalizedURL,
+			"namespace":      e.Key.Namespace,
+			"variant":        e.Key.Variant,
+			"string":         e.Key.String(),
+		},
+		"bypass": e.Bypass,
+		"cached": e.State != "",
+		"source": e.Source,
+	}
+	if e.State != "" {
+		body["entry"] = gin.H{
+			"state":       e.State,
+			"stored_at":   unixTime(e.StoredAt),
+			"expires_at":  unixTime(e.ExpiresAt),
+			"stale_until": unixTime(e.StaleUntil),
+			"freshness":   e.Freshness,
+		}
+	}
+	if e.AdaptiveTTL > 0 || e.BackoffUntil > 0 {
+		body["metadata"] = gin.H{
+			"adaptive_ttl_seconds": e.AdaptiveTTL.Seconds(),
+			"backoff_until":        unixTime(e.BackoffUntil),
+		}
+	}
+	if e.FetchError != "" {
+		body["fetch_error"] = e.FetchError
+	}
+	if e.Decision != nil {
+		warnings := e.Decision.Warnings
+		if warnings == nil {
+			warnings = []string{}
+		}
+		body["origin_status"] = e.OriginStatus
+		body["decision"] = gin.H{
+			"cacheable":   e.Decision.Cacheable,
+			"ttl_seconds": e.Decision.TTL.Seconds(),
+			"expires_at":  unixTime(e.Decision.ExpiresAt),
+			"freshness":   e.Decision.Freshness,
+			"rule":        e.Decision.Rule,
+			"reason":      e.Decision.Reason,
+			"warnings":    warnings,
+		}
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// entryJSON renders a cache entry. Bodies that are not valid UTF-8, e.g. compressed ones,
+// are base64 encoded.
+func entryJSON(e entity.CacheEntryInfo) gin.H {
+	headers := e.Headers
+	if headers == nil {
+		headers = http.Header{}
+	}
+	body := gin.H{
+		"key":            e.Key.String(),
+		"method":         e.Key.Method,
+		"normalized_url": e.Key.NormalizedURL,
+		"namespace":      e.Key.Namespace,
+		"variant":        e.Key.Variant,
+		"size":           e.Size,
+		"stored_at":      unixTime(e.StoredAt),
+		"expires_at":     unixTime(e.ExpiresAt),
+		"stale_until":    unixTime(e.StaleUntil),
+		"hits":           e.Hits,
+		"status":         e.Status,
+		"headers":        headers,
+	}
+	if e.Body != nil {
+		if utf8.Valid(e.Body) {
+			body["body"], body["body_encoding"] = string(e.Body), "utf-8"
+		} else {
+			body["body"], body["body_encoding"] = base64.StdEncoding.EncodeToString(e.Body), "base64"
+		}
+	}
+	return body
+}
+
+// queryLimit parses ?limit=, answering 400 itself when it is not a positive integer.
+func queryLimit(c *gin.Context, fallback int) (int, bool) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return fallback, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return 0, false
+	}
+	return n, true
+}
+
+// unixTime renders a unix timestamp as RFC 3339, or nil when it is unset.
+func unixTime(ts int64) any {
+	if ts <= 0 {
+		return nil
+	}
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
+
+func windowJSON(m entity.Metrics) gin.H {
+	prefixes := make([]gin.H, 0, len(m.TopEvictedPrefixes))
+	for _, p := range m.TopEvictedPrefixes {
+		prefixes = append(prefixes, gin.H{"prefix": p.Prefix, "count": p.Count})
+	}
+	return gin.H{
+		"requests":             m.Requests,
+		"hits":                 m.Hits,
+		"misses":               m.Misses,
+		"hit_ratio":            m.HitRatio,
+		"bytes_served":         m.BytesServed,
+		"bytes_from_cache":     m.BytesFromCache,
+		"byte_hit_ratio":       m.ByteHitRatio,
+		"evictions":            m.Evictions,
+		"top_evicted_prefixes": prefixes,
+		"origin": gin.H{
+			"requests":   m.OriginRequests,
+			"errors":     m.OriginErrors,
+			"error_rate": m.OriginErrorRate,
+			"retries":    m.OriginRetries,
+			"connections": gin.H{
+				"reused": m.OriginConnsReused,
+				"new":    m.OriginConnsNew,
+			},
+		},
+		"rate_limited": m.RateLimited,
+		"latency_ms": gin.H{
+			"total":    latencyJSON(m.TotalLatency),
+			"upstream": latencyJSON(m.UpstreamLatency),
+			"cache":    latencyJSON(m.CacheLatency),
+		},
+	}
+}
+
+func latencyJSON(l entity.LatencySummary) gin.H {
+	return gin.H{
+		"count": l.Count,
+		"p50":   millis(l.P50),
+		"p90":   millis(l.P90),
+		"p95":   millis(l.P95),
+		"p99":   millis(l.P99),
+		"max":   millis(l.Max),
+	}
+}
+
+// windowLabel renders windows the way they are usually written, e.g. "5m" or "1h".
+func windowLabel(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute && d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return d.String()
+	}
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// dashboardFS serves the single-page admin dashboard, which reads everything it shows from
+// the admin API.
+func dashboardFS() http.FileSystem {
+	files, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		panic(err)
+	}
+	return http.FS(files)
+}
@@ -0,0 +1,261 @@
+"use strict";
+
+// The dashboard polls the admin API; the token is kept for the browser tab only.
+const API = "/api/v1";
+const POLL_MS = 5000;
+const HISTORY = 120;
+
+const history = { hit: [], byte: [], p50: [], p95: [], p99: [] };
+let token = sessionStorage.getItem("revprox-admin-token") || "";
+
+const $ = (id) => document.getElementById(id);
+
+async function api(path, options = {}) {
+  const headers = {};
+  if (token) headers["Authorization"] = "Bearer " + token;
+  const res = await fetch(API + path, { ...options, headers });
+  const body = await res.json().catch(() => ({}));
+  if (!res.ok) throw new Error(body.error || res.status + " " + res.statusText);
+  return body;
+}
+
+function setStatus(text, ok) {
+  const el = $("status");
+  el.textContent = text;
+  el.className = "status " + (ok ? "ok" : "error");
+}
+
+function percent(ratio) {
+  return (ratio * 100).toFixed(1) + "%";
+}
+
+function bytes(n) {
+  const units = ["B", "KB", "MB", "GB", "TB"];
+  let i = 0;
+  while (n >= 1024 && i < units.length - 1) {
+    n /= 1024;
+    i++;
+  }
+  return n.toFixed(i ? 1 : 0) + " " + units[i];
+}
+
+function time(ts) {
+  return ts ? new Date(ts).toLocaleString() : "-";
+}
+
+function cell(row, text, className) {
+  const td = document.createElement("td");
+  td.textContent = text;
+  if (className) td.className = className;
+  row.appendChild(td);
+  return td;
+}
+
+function purgeButton(row, key) {
+  const td = document.createElement("td");
+  const button = document.createElement("button");
+  button.className = "danger";
+  button.textContent = "Purge";
+  button.onclick = () => purge({ key });
+  td.appendChild(button);
+  row.appendChild(td);
+}
+
+function push(series, value) {
+  series.push(value);
+  if (series.length > HISTORY) series.shift();
+}
+
+function drawChart(svg, lines, max) {
+  svg.innerHTML = "";
+  const [, , width, height] = svg.getAttribute("viewBox").split(" ").map(Number);
+  for (const { values, color } of lines) {
+    const step = width / (HISTORY - 1);
+    const offset = HISTORY - values.length;
+    const points = values.map((v, i) => {
+      const x = (offset + i) * step;
+      const y = height - (max > 0 ? (v / max) * height : 0);
+      return x.toFixed(1) + "," + y.toFixed(1);
+    });
+    const line = document.createElementNS("http://www.w3.org/2000/svg", "polyline");
+    line.setAttribute("points", points.join(" "));
+    line.setAttribute("stroke", color);
+    svg.appendChild(line);
+  }
+}
+
+async function refreshStats() {
+  const stats = await api("/admin/stats");
+  const minute = stats.windows["1m"];
+  $("hit-ratio").textContent = percent(minute.hit_ratio);
+  $("byte-hit-ratio").textContent = percent(minute.byte_hit_ratio);
+  $("requests").textContent = minute.requests;
+  $("entries").textContent = stats.cache.entries;
+  $("cache-used").textContent = bytes(stats.cache.bytes) + " / " + bytes(stats.cache.max_bytes);
+
+  push(history.hit, minute.hit_ratio);
+  push(history.byte, minute.byte_hit_ratio);
+  push(history.p50, minute.latency_ms.total.p50);
+  push(history.p95, minute.latency_ms.total.p95);
+  push(history.p99, minute.latency_ms.total.p99);
+  const css = getComputedStyle(document.documentElement);
+  const color = (name) => css.getPropertyValue(name).trim();
+  drawChart($("hit-chart"), [
+    { values: history.hit, color: color("--hit") },
+    { values: history.byte, color: color("--byte") },
+  ], 1);
+  const latencyMax = Math.max(1, ...history.p99);
+  $("latency-max").textContent = "max " + latencyMax.toFixed(1) + " ms";
+  drawChart($("latency-chart"), [
+    { values: history.p50, color: color("--p50") },
+    { values: history.p95, color: color("--p95") },
+    { values: history.p99, color: color("--p99") },
+  ], latencyMax);
+
+  const tbody = $("origin-table").querySelector("tbody");
+  tbody.innerHTML = "";
+  for (const label of ["1m", "5m", "1h"]) {
+    const w = stats.windows[label];
+    if (!w) continue;
+    const row = document.createElement("tr");
+    cell(row, label);
+    cell(row, w.origin.requests);
+    cell(row, percent(w.origin.error_rate));
+    const errors = Object.entries(w.origin.errors || {}).map(([kind, n]) => kind + ": " + n);
+    cell(row, errors.join(", ") || "-");
+    cell(row, w.latency_ms.upstream.p95.toFixed(1));
+    cell(row, w.evictions);
+    tbody.appendChild(row);
+  }
+}
+
+async function refreshReadiness() {
+  try {
+    const res = await fetch(API + "/health/readyz");
+    const body = await res.json();
+    $("origin-ready").textContent = body.status;
+  } catch (err) {
+    $("origin-ready").textContent = "unknown";
+  }
+}
+
+async function refreshHotKeys() {
+  const hot = await api("/admin/hot-keys?limit=10&by=" + $("hot-by").value);
+  const tbody = $("hot-table").querySelector("tbody");
+  tbody.innerHTML = "";
+  for (const k of hot.items) {
+    const row = document.createElement("tr");
+    cell(row, k.key, "key");
+    cell(row, k.requests);
+    cell(row, k.misses);
+    cell(row, bytes(k.bytes));
+    cell(row, k.origin_time_ms.toFixed(1));
+    purgeButton(row, k.key);
+    tbody.appendChild(row);
+  }
+}
+
+let entryCursor = "";
+
+function entryFilter() {
+  const text = $("entry-query").value;
+  // the search box matches anywhere in the key, ignoring case
+  const regex = text ? "(?i)" + text.replace(/[.*+?^${}()|[\]\\]/g, "\\$&") : "";
+  return "&regex=" + encodeURIComponent(regex);
+}
+
+async function refreshEntries(more = false) {
+  if (!more) entryCursor = "";
+  const entries = await api("/admin/entries?limit=100" + entryFilter() + "&cursor=" + encodeURIComponent(entryCursor));
+  const tbody = $("entry-table").querySelector("tbody");
+  if (!more) tbody.innerHTML = "";
+  for (const e of entries.items) {
+    const row = document.createElement("tr");
+    cell(row, e.key, "key");
+    cell(row, e.status);
+    cell(row, e.hits);
+    cell(row, bytes(e.size));
+    cell(row, time(e.stored_at));
+    cell(row, time(e.expires_at));
+    const td = document.createElement("td");
+    const show = document.createElement("button");
+    show.textContent = "Details";
+    show.onclick = () => showEntry(e.key);
+    td.appendChild(show);
+    row.appendChild(td);
+    purgeButton(row, e.key);
+    tbody.appendChild(row);
+  }
+  entryCursor = entries.next_cursor;
+  $("entry-more").hidden = !entryCursor;
+  $("entry-total").textContent = "(" + tbody.children.length + " of " + entries.total + ")";
+}
+
+async function showEntry(key) {
+  try {
+    const entry = await api("/admin/entry?body=true&key=" + encodeURIComponent(key));
+    const detail = $("entry-detail");
+    detail.textContent = JSON.stringify(entry, null, 2);
+    detail.hidden = false;
+  } catch (err) {
+    setStatus(err.message, false);
+  }
+}
+
+async function refreshConfig() {
+  $("config").textContent = JSON.stringify(await api("/admin/config"), null, 2);
+}
+
+async function purge(params) {
+  const target = params.key || params.prefix + "*";
+  if (!confirm("Purge " + target + "?")) return;
+  try {
+    const result = await api("/admin/entries?" + new URLSearchParams(params), { method: "DELETE" });
+    setStatus("purged " + result.purged + " entries", true);
+    await refreshEntries();
+  } catch (err) {
+    setStatus("purge failed: " + err.message, false);
+  }
+}
+
+async function poll() {
+  try {
+    await Promise.all([refreshStats(), refreshHotKeys(), refreshReadiness()]);
+    setStatus("updated " + new Date().toLocaleTimeString(), true);
+  } catch (err) {
+    setStatus(err.message, false);
+  }
+}
+
+async function connect() {
+  try {
+    await Promise.all([poll(), refreshEntries(), refreshConfig()]);
+  } catch (err) {
+    setStatus(err.message, false);
+  }
+}
+
+$("token").value = token;
+$("token-form").onsubmit = (event) => {
+  event.preventDefault();
+  token = $("token").value;
+  sessionStorage.setItem("revprox-admin-token", token);
+  connect();
+};
+$("entry-search").onsubmit = (event) => {
+  event.preventDefault();
+  refreshEntries().catch((err) => setStatus(err.message, false));
+};
+$("purge-prefix").onclick = () => {
+  const prefix = $("entry-query").value;
+  if (!prefix) {
+    setStatus("enter a key prefix to purge", false);
+    return;
+  }
+  purge({ prefix });
+};
+$("entry-more").onclick = () => refreshEntries(true).catch((err) => setStatus(err.message, false));
+$("hot-by").onchange = () => refreshHotKeys().catch((err) => setStatus(err.message, false));
+
+connect();
+setInterval(poll, POLL_MS);
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// cacheOutcomeContextKey is where handlers leave the cache outcome for the metrics middleware.
+const cacheOutcomeContextKey = "cache_outcome"
+
+// MetricsMiddleware records per-route request counts and durations.
+type MetricsMiddleware struct {
+	metrics     contract.IMetricsAdapter
+	timeService contract.ITimeService
+	logger      contract.ILogger
+}
+
+func NewMetricsMiddleware(metrics contract.IMetricsAdapter, timeService contract.ITimeService, logger contract.ILogger) *MetricsMiddleware {
+	return &MetricsMiddleware{metrics: metrics, timeService: timeService, logger: logger}
+}
+
+func (m *MetricsMiddleware) Handle(c *gin.Context) {
+	start := m.timeService.Monotonic()
+	c.Next()
+
+	// FullPath is the matched route template, which keeps the label bounded.
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	outcome := valueobject.CacheOutcomeNone
+	if value, ok := c.Get(cacheOutcomeContextKey); ok {
+		if o, ok := value.(valueobject.CacheOutcome); ok && o != "" {
+			outcome = o
+		}
+	}
+	ctx := c.Request.Context()
+	if err := m.metrics.RecordRequest(ctx, route, c.Request.Method, c.Writer.Status(), outcome, c.Writer.Size(), m.timeService.Since(start)); err != nil {
+		m.logger.Error(ctx, "Metrics RecordRequest error", valueobject.LogField{Key: "error", Value: err.Error()})
+	}
+}
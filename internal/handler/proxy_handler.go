@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"net/url"
@@ -9,15 +10,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/mikiasgoitom/RevProx/internal/contract"
 	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
 )
 
 type ProxyHandler struct {
 	proxyUsecase contract.IProxyUseCase
 	logger       contract.ILogger
+	serverTiming *ServerTiming
 }
 
-func NewProxyHandler(proxyUC contract.IProxyUseCase, logger contract.ILogger) *ProxyHandler {
-	return &ProxyHandler{proxyUsecase: proxyUC, logger: logger}
+func NewProxyHandler(proxyUC contract.IProxyUseCase, logger contract.ILogger, serverTiming *ServerTiming) *ProxyHandler {
+	return &ProxyHandler{proxyUsecase: proxyUC, logger: logger, serverTiming: serverTiming}
 }
 
 func (h *ProxyHandler) HandleProxy(c *gin.Context) {
@@ -41,7 +44,9 @@ func (h *ProxyHandler) HandleProxy(c *gin.Context) {
         RawQuery: rawQuery,
     }
 
+    requestInfo, _ := valueobject.RequestInfoFromContext(c.Request.Context())
     reqModel := entity.RequestModel{
+        ID:       requestInfo.RequestID,
         Method:   c.Request.Method,
         URL:      originURL, 
         Headers:  c.Request.Header,
@@ -52,10 +57,24 @@ func (h *ProxyHandler) HandleProxy(c *gin.Context) {
     // Call the proxy use case
     respModel, err := h.proxyUsecase.ServeProxyRequest(c.Request.Context(), reqModel)
     if err != nil {
+        var originErr *entity.OriginError
+        if errors.As(err, &originErr) && originErr.Kind == entity.OriginErrorOverloaded {
+            // the request never reached the origin; it is safe to retry shortly
+            c.Header("Retry-After", "1")
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "upstream overloaded", "details": err.Error()})
+            return
+        }
+        if errors.As(err, &originErr) && originErr.Kind == entity.OriginErrorTimeout {
+            c.JSON(http.StatusGatewayTimeout, gin.H{"error": "upstream timeout", "details": err.Error()})
+            return
+        }
         c.JSON(http.StatusBadGateway, gin.H{"error": "upstream service error", "details": err.Error()})
         return
     }
 
+    c.Set(cacheOutcomeContextKey, respModel.CacheOutcome)
+    c.Set(upstreamTimingContextKey, respModel.Timing)
+
     // Write the ResponseModel back to the client
     // Copy headers from the response model to the Gin response
     for key, values := range respModel.Headers {
@@ -64,9 +83,17 @@ func (h *ProxyHandler) HandleProxy(c *gin.Context) {
         if key == "Content-Encoding" && strings.Contains(values[0], "gzip") {
             continue
         }
+        // The request ID was already set by RequestIDMiddleware; a cached copy echoed by
+        // the origin would carry the ID of the request that filled the cache.
+        if key == valueobject.RequestIDHeader {
+            continue
+        }
         for _, value := range values {
             c.Writer.Header().Add(key, value)
         }
     }
+    if h.serverTiming.enabled(c, originPath) {
+        c.Writer.Header().Add(serverTimingHeader, h.serverTiming.header(respModel))
+    }
     c.Data(respModel.Status, respModel.Headers.Get("Content-Type"), respModel.Body)
 }
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// defaultRateLimitRule names the fallback rule in metrics.
+const defaultRateLimitRule = "default"
+
+// RateLimitMiddleware applies token bucket limits to proxied requests and advertises them
+// with the RateLimit-* headers of the IETF rate limit headers draft.
+type RateLimitMiddleware struct {
+	enabled      bool
+	apiKeyHeader string
+	// apiKeys maps known API keys to their client names.
+	apiKeys map[string]string
+	// rules are ordered longest prefix first; the default rule, if any, is last.
+	rules       []rateLimitRule
+	limiter     contract.IRateLimiter
+	metrics     contract.IMetricsAdapter
+	timeService contract.ITimeService
+	logger      contract.ILogger
+}
+
+type rateLimitRule struct {
+	name   string
+	prefix string
+	key    valueobject.RateLimitKey
+	limit  valueobject.RateLimit
+	policy string
+}
+
+func NewRateLimitMiddleware(cfg config.RateLimitConfig, limiter contract.IRateLimiter, metrics contract.IMetricsAdapter, timeService contract.ITimeService, logger contract.ILogger) (*RateLimitMiddleware, error) {
+	m := &RateLimitMiddleware{
+		enabled:      cfg.Enabled,
+		apiKeyHeader: cfg.APIKeyHeader,
+		apiKeys:      make(map[string]string, len(cfg.APIKeys)),
+		limiter:      limiter,
+		metrics:      metrics,
+		timeService:  timeService,
+		logger:       logger,
+	}
+	if !cfg.Enabled {
+		return m, nil
+	}
+	for name, key := range cfg.APIKeys {
+		if key == "" {
+			return nil, fmt.Errorf("rate_limit.api_keys.%s is empty", name)
+		}
+		m.apiKeys[key] = name
+	}
+	for i, route := range cfg.Routes {
+		if !strings.HasPrefix(route.PathPrefix, "/") {
+			return nil, fmt.Errorf("rate_limit.routes[%d].path_prefix must start with /", i)
+		}
+		rule, err := newRateLimitRule(route.PathPrefix, route)
+		if err != nil {
+			return nil, fmt.Errorf("rate_limit.routes[%d]: %w", i, err)
+		}
+		m.rules = append(m.rules, rule)
+	}
+	sort.SliceStable(m.rules, func(i, j int) bool { return len(m.rules[i].prefix) > len(m.rules[j].prefix) })
+	if cfg.Default.Limit > 0 {
+		rule, err := newRateLimitRule(defaultRateLimitRule, cfg.Default)
+		if err != nil {
+			return nil, fmt.Errorf("rate_limit.default: %w", err)
+		}
+		rule.prefix = "/"
+		m.rules = append(m.rules, rule)
+	}
+	for _, rule := range m.rules {
+		if rule.key == valueobject.RateLimitByAPIKey && len(m.apiKeys) == 0 {
+			return nil, fmt.Errorf("rate limit rule %q is keyed on api_key but rate_limit.api_keys is empty", rule.name)
+		}
+	}
+	return m, nil
+}
+
+func newRateLimitRule(name string, cfg config.RateLimitRule) (rateLimitRule, error) {
+	key := valueobject.RateLimitKey(cfg.Key)
+	switch key {
+	case "":
+		key = valueobject.RateLimitByIP
+	case valueobject.RateLimitByIP, valueobject.RateLimitByAPIKey, valueobject.RateLimitByRoute:
+	default:
+		return rateLimitRule{}, fmt.Errorf("unknown key %q, want ip, api_key or route", cfg.Key)
+	}
+	if cfg.Limit <= 0 {
+		return rateLimitRule{}, fmt.Errorf("limit must be positive")
+	}
+	windowSeconds := cfg.WindowSeconds
+	if windowSeconds <= 0 {
+		windowSeconds = 1
+	}
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = cfg.Limit
+	}
+	return rateLimitRule{
+		name:   name,
+		prefix: cfg.PathPrefix,
+		key:    key,
+		limit: valueobject.RateLimit{
+			Limit:  cfg.Limit,
+			Window: time.Duration(windowSeconds) * time.Second,
+			Burst:  burst,
+		},
+		policy: fmt.Sprintf("%d;w=%d;burst=%d", cfg.Limit, windowSeconds, burst),
+	}, nil
+}
+
+func (m *RateLimitMiddleware) Handle(c *gin.Context) {
+	if !m.enabled {
+		c.Next()
+		return
+	}
+	rule, ok := m.match(c.Param("path"))
+	if !ok {
+		c.Next()
+		return
+	}
+
+	decision := m.limiter.Allow(m.bucketKey(c, rule), rule.limit, m.timeService.Now())
+	c.Header("RateLimit-Policy", rule.policy)
+	c.Header("RateLimit-Limit", strconv.Itoa(decision.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	c.Header("RateLimit-Reset", strconv.FormatInt(ceilSeconds(decision.Reset), 10))
+	if decision.Allowed {
+		c.Next()
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := m.metrics.RecordRateLimited(ctx, rule.name, rule.key); err != nil {
+		m.logger.Error(ctx, "Metrics RecordRateLimited error", valueobject.LogField{Key: "error", Value: err.Error()})
+	}
+	retryAfter := ceilSeconds(decision.RetryAfter)
+	c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":       "rate limit exceeded",
+		"retry_after": retryAfter,
+	})
+}
+
+func (m *RateLimitMiddleware) match(path string) (rateLimitRule, bool) {
+	for _, rule := range m.rules {
+		if strings.HasPrefix(path, rule.prefix) {
+			return rule, true
+		}
+	}
+	return rateLimitRule{}, false
+}
+
+// bucketKey scopes buckets to their rule so a client's usage of one route does not count
+// against another. API keys are only trusted when known; anything else is keyed on the
+// client IP, which gin takes from forwarding headers only when the peer is a trusted proxy.
+func (m *RateLimitMiddleware) bucketKey(c *gin.Context, rule rateLimitRule) string {
+	switch rule.key {
+	case valueobject.RateLimitByRoute:
+		return rule.name
+	case valueobject.RateLimitByAPIKey:
+		if client, ok := m.apiKeys[c.GetHeader(m.apiKeyHeader)]; ok {
+			return rule.name + "|key:" + client
+		}
+	}
+	return rule.name + "|ip:" + c.ClientIP()
+}
+
+// ceilSeconds rounds up so clients never retry before a token is available.
+func ceilSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
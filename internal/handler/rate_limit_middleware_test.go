@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/config"
+)
+
+func TestRateLimitMiddlewareBucketKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.RateLimitConfig{
+		Enabled:      true,
+		APIKeyHeader: "X-API-Key",
+		APIKeys:      map[string]string{"mobile": "secret-1"},
+		Default:      config.RateLimitRule{Key: "api_key", Limit: 10},
+	}
+	m, err := NewRateLimitMiddleware(cfg, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		headers        http.Header
+		want           string
+	}{
+		{
+			name:    "known key",
+			headers: http.Header{"X-Api-Key": {"secret-1"}},
+			want:    "default|key:mobile",
+		},
+		{
+			name:    "unknown key falls back to the peer address",
+			headers: http.Header{"X-Api-Key": {"made-up"}},
+			want:    "default|ip:10.0.0.1",
+		},
+		{
+			name: "no key",
+			want: "default|ip:10.0.0.1",
+		},
+		{
+			name:    "forwarded address from an untrusted peer is ignored",
+			headers: http.Header{"X-Forwarded-For": {"203.0.113.7"}},
+			want:    "default|ip:10.0.0.1",
+		},
+		{
+			name:           "forwarded address from a trusted proxy is used",
+			trustedProxies: []string{"10.0.0.0/8"},
+			headers:        http.Header{"X-Forwarded-For": {"203.0.113.7"}},
+			want:           "default|ip:203.0.113.7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, engine := gin.CreateTestContext(httptest.NewRecorder())
+			if err := engine.SetTrustedProxies(tt.trustedProxies); err != nil {
+				t.Fatal(err)
+			}
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/proxy/items", nil)
+			c.Request.RemoteAddr = "10.0.0.1:5000"
+			for name, values := range tt.headers {
+				c.Request.Header[name] = values
+			}
+			if got := m.bucketKey(c, m.rules[0]); got != tt.want {
+				t.Fatalf("bucketKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRateLimitMiddlewareRequiresKnownAPIKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.RateLimitConfig
+		wantErr bool
+	}{
+		{
+			name: "api_key rule without keys",
+			cfg: config.RateLimitConfig{
+				Enabled: true,
+				Routes:  []config.RateLimitRule{{PathPrefix: "/search", Key: "api_key", Limit: 5}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty key",
+			cfg: config.RateLimitConfig{
+				Enabled: true,
+				APIKeys: map[string]string{"mobile": ""},
+				Default: config.RateLimitRule{Key: "api_key", Limit: 5},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ip rule without keys",
+			cfg: config.RateLimitConfig{
+				Enabled: true,
+				Default: config.RateLimitRule{Limit: 5},
+			},
+		},
+		{
+			name: "disabled",
+			cfg: config.RateLimitConfig{
+				Default: config.RateLimitRule{Key: "api_key", Limit: 5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRateLimitMiddleware(tt.cfg, nil, nil, nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRateLimitMiddleware error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// maxRequestIDLength bounds client supplied request IDs so they cannot bloat logs.
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware accepts the client's X-Request-ID or generates one, stores it in the
+// request context together with the client IP and route, and echoes it in the response.
+type RequestIDMiddleware struct{}
+
+func NewRequestIDMiddleware() *RequestIDMiddleware {
+	return &RequestIDMiddleware{}
+}
+
+func (m *RequestIDMiddleware) Handle(c *gin.Context) {
+	requestID := c.GetHeader(valueobject.RequestIDHeader)
+	if !validRequestID(requestID) {
+		requestID = uuid.NewString()
+	}
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+
+	c.Request.Header.Set(valueobject.RequestIDHeader, requestID)
+	c.Header(valueobject.RequestIDHeader, requestID)
+	ctx := valueobject.WithRequestInfo(c.Request.Context(), valueobject.RequestInfo{
+		RequestID: requestID,
+		ClientIP:  c.ClientIP(),
+		Route:     route,
+	})
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
+
+// validRequestID accepts non-empty IDs of printable ASCII without spaces.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
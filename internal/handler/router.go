@@ -1,41 +1,108 @@
 package handler
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
 
 type Router struct {
-	healthCheckHandler *HealthHandler
-	prometheusHandler  *PrometheusHandler
-	proxyHandler       *ProxyHandler
+	healthCheckHandler  *HealthHandler
+	prometheusHandler   *PrometheusHandler
+	proxyHandler        *ProxyHandler
+	adminHandler        *AdminHandler
+	snapshotHandler     *SnapshotHandler
+	warmupHandler       *WarmupHandler
+	metricsMiddleware   *MetricsMiddleware
+	tracingMiddleware   *TracingMiddleware
+	requestIDMiddleware *RequestIDMiddleware
+	accessLogMiddleware *AccessLogMiddleware
+	adminAuthMiddleware *AdminAuthMiddleware
+	rateLimitMiddleware *RateLimitMiddleware
 }
 
 func NewRouter(
 	healthCheckHandler *HealthHandler,
 	prometheusHandler *PrometheusHandler,
 	proxyHandler *ProxyHandler,
+	adminHandler *AdminHandler,
+	snapshotHandler *SnapshotHandler,
+	warmupHandler *WarmupHandler,
+	metricsMiddleware *MetricsMiddleware,
+	tracingMiddleware *TracingMiddleware,
+	requestIDMiddleware *RequestIDMiddleware,
+	accessLogMiddleware *AccessLogMiddleware,
+	adminAuthMiddleware *AdminAuthMiddleware,
+	rateLimitMiddleware *RateLimitMiddleware,
 ) *Router {
 	return &Router{
-		healthCheckHandler: healthCheckHandler,
-		prometheusHandler:  prometheusHandler,
-		proxyHandler:       proxyHandler,
+		healthCheckHandler:  healthCheckHandler,
+		prometheusHandler:   prometheusHandler,
+		proxyHandler:        proxyHandler,
+		adminHandler:        adminHandler,
+		snapshotHandler:     snapshotHandler,
+		warmupHandler:       warmupHandler,
+		metricsMiddleware:   metricsMiddleware,
+		tracingMiddleware:   tracingMiddleware,
+		requestIDMiddleware: requestIDMiddleware,
+		accessLogMiddleware: accessLogMiddleware,
+		adminAuthMiddleware: adminAuthMiddleware,
+		rateLimitMiddleware: rateLimitMiddleware,
 	}
 }
 
+// SetupRoutes registers the public routes: health checks and the proxy.
 func (r *Router) SetupRoutes(router *gin.Engine) {
+	router.Use(r.requestIDMiddleware.Handle, r.accessLogMiddleware.Handle, r.tracingMiddleware.Handle, r.metricsMiddleware.Handle)
 	baseUrl := router.Group("/api/v1")
-	metrics := baseUrl.Group("/metrics")
+	r.setupHealthRoutes(baseUrl)
+	proxy := router.Group(valueobject.ProxyRoutePrefix, r.rateLimitMiddleware.Handle)
+	{
+		// This is the correct implementation for a catch-all proxy route.
+		// "Any" matches all HTTP methods (GET, POST, PUT, etc.).
+		// "/*path" is a wildcard that matches any path after /proxy/.
+		proxy.Any("/*path", r.proxyHandler.HandleProxy)
+	}
+}
+
+// SetupAdminRoutes registers the metrics and admin routes behind authentication and the
+// dashboard under /admin/. On a
+// separate admin listener the health checks are served there as well.
+func (r *Router) SetupAdminRoutes(router *gin.Engine, separateListener bool) {
+	if separateListener {
+		router.Use(r.requestIDMiddleware.Handle, r.accessLogMiddleware.Handle)
+	}
+	baseUrl := router.Group("/api/v1")
+	if separateListener {
+		r.setupHealthRoutes(baseUrl)
+	}
+	metrics := baseUrl.Group("/metrics", r.adminAuthMiddleware.Authenticate)
 	{
 		metrics.GET("/prometheus", r.prometheusHandler.GetMetrics)
 	}
+	admin := baseUrl.Group("/admin", r.adminAuthMiddleware.Audit, r.adminAuthMiddleware.Authenticate)
+	{
+		admin.GET("/stats", r.adminHandler.Stats)
+		admin.GET("/hot-keys", r.adminHandler.HotKeys)
+		admin.GET("/hot-clients", r.adminHandler.HotClients)
+		admin.GET("/explain", r.adminHandler.Explain)
+		admin.GET("/entries", r.adminHandler.Entries)
+		admin.GET("/entry", r.adminHandler.Entry)
+		admin.DELETE("/entries", r.adminHandler.Purge)
+		admin.GET("/config", r.adminHandler.Config)
+		admin.GET("/snapshot", r.snapshotHandler.Export)
+		admin.POST("/snapshot", r.snapshotHandler.Import)
+		admin.GET("/warmup", r.warmupHandler.Progress)
+		admin.POST("/warmup", r.warmupHandler.Start)
+	}
+	// the dashboard's files are public within the allowlist; its API calls carry the token
+	dashboard := router.Group("/admin", r.adminAuthMiddleware.Allowlist)
+	dashboard.StaticFS("/", dashboardFS())
+}
+
+func (r *Router) setupHealthRoutes(baseUrl *gin.RouterGroup) {
 	health := baseUrl.Group("/health")
 	{
 		health.GET("/livez", r.healthCheckHandler.Liveness)
 		health.GET("/readyz", r.healthCheckHandler.Readiness)
 	}
-	proxy := baseUrl.Group("/proxy")
-	{
-        // This is the correct implementation for a catch-all proxy route.
-        // "Any" matches all HTTP methods (GET, POST, PUT, etc.).
-        // "/*path" is a wildcard that matches any path after /proxy/.
-        proxy.Any("/*path", r.proxyHandler.HandleProxy)
-    }
-}
\ No newline at end of file
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+const serverTimingHeader = "Server-Timing"
+
+// ServerTiming decides which proxied responses expose the proxy's phases to browsers and
+// renders the Server-Timing header for them.
+type ServerTiming struct {
+	cfg config.ServerTimingConfig
+}
+
+func NewServerTiming(cfg config.ServerTimingConfig) *ServerTiming {
+	return &ServerTiming{cfg: cfg}
+}
+
+// enabled reports whether the request to the proxied path asked for, or is configured to
+// get, the header.
+func (s *ServerTiming) enabled(c *gin.Context, proxiedPath string) bool {
+	if s.cfg.Enabled {
+		return true
+	}
+	// config validation guarantees a trigger value whenever a trigger header is set
+	if s.cfg.TriggerHeader != "" && s.cfg.TriggerValue != "" {
+		if value := c.GetHeader(s.cfg.TriggerHeader); subtle.ConstantTimeCompare([]byte(value), []byte(s.cfg.TriggerValue)) == 1 {
+			return true
+		}
+	}
+	for _, prefix := range s.cfg.PathPrefixes {
+		if strings.HasPrefix(proxiedPath, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// header renders resp's timings, e.g.
+// `cache-lookup;dur=0.04, origin;dur=12.5, origin-ttfb;dur=11.8, cache-write;dur=0.02, total;dur=12.7;desc="miss"`.
+func (s *ServerTiming) header(resp entity.ResponseModel) string {
+	t := resp.ProxyTiming
+	entries := make([]string, 0, 5)
+	if t.CacheLookup > 0 {
+		entries = append(entries, timingEntry("cache-lookup", t.CacheLookup))
+	}
+	if t.OriginFetch > 0 {
+		entries = append(entries, timingEntry("origin", t.OriginFetch))
+		if resp.Timing.TTFB > 0 {
+			entries = append(entries, timingEntry("origin-ttfb", resp.Timing.TTFB))
+		}
+	}
+	if t.CacheWrite > 0 {
+		entries = append(entries, timingEntry("cache-write", t.CacheWrite))
+	}
+	entries = append(entries, fmt.Sprintf("%s;desc=%q", timingEntry("total", t.Total), string(resp.CacheOutcome)))
+	return strings.Join(entries, ", ")
+}
+
+func timingEntry(name string, d time.Duration) string {
+	return fmt.Sprintf("%s;dur=%.3f", name, float64(d)/float64(time.Millisecond))
+}
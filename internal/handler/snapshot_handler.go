@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// SnapshotHandler downloads and uploads cache snapshot archives.
+type SnapshotHandler struct {
+	snapshotUseCase contract.ISnapshotUseCase
+	timeService     contract.ITimeService
+	logger          contract.ILogger
+}
+
+func NewSnapshotHandler(snapshotUC contract.ISnapshotUseCase, timeService contract.ITimeService, logger contract.ILogger) *SnapshotHandler {
+	return &SnapshotHandler{snapshotUseCase: snapshotUC, timeService: timeService, logger: logger}
+}
+
+// Export streams the whole cache as a snapshot archive.
+func (h *SnapshotHandler) Export(c *gin.Context) {
+	filename := "revprox-" + h.timeService.Now().UTC().Format("20060102T150405Z") + ".snapshot.gz"
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	// the status is already sent once the archive starts streaming, so failures can only be logged
+	if _, err := h.snapshotUseCase.Export(c.Request.Context(), c.Writer); err != nil {
+		h.logger.Error(c.Request.Context(), "Cache snapshot export failed", valueobject.LogField{Key: "error", Value: err.Error()})
+		c.Abort()
+	}
+}
+
+// Import loads the snapshot archive in the request body into the cache.
+func (h *SnapshotHandler) Import(c *gin.Context) {
+	result, err := h.snapshotUseCase.Import(c.Request.Context(), c.Request.Body)
+	body := snapshotImportJSON(result)
+	if err != nil {
+		body["error"] = "snapshot import failed"
+		body["details"] = err.Error()
+		c.JSON(http.StatusBadRequest, body)
+		return
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+func snapshotImportJSON(result entity.SnapshotImport) gin.H {
+	archive := gin.H{"version": result.Archive.Version, "entries": result.Archive.Entries, "created_at": nil}
+	if !result.Archive.CreatedAt.IsZero() {
+		archive["created_at"] = result.Archive.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	return gin.H{
+		"archive": archive,
+		"loaded":  result.Loaded,
+		"dropped": result.Dropped,
+		"expired": result.Expired,
+		"failed":  result.Failed,
+	}
+}
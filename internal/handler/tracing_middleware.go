@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// TracingMiddleware opens the server span for every inbound request, continuing the
+// client's trace when it sent a traceparent header.
+type TracingMiddleware struct {
+	tracer contract.ITracer
+}
+
+func NewTracingMiddleware(tracer contract.ITracer) *TracingMiddleware {
+	return &TracingMiddleware{tracer: tracer}
+}
+
+func (m *TracingMiddleware) Handle(c *gin.Context) {
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	ctx := m.tracer.Extract(c.Request.Context(), c.Request.Header)
+	ctx, span := m.tracer.Start(ctx, c.Request.Method+" "+route, valueobject.SpanKindServer,
+		valueobject.LogField{Key: "http.request.method", Value: c.Request.Method},
+		valueobject.LogField{Key: "http.route", Value: route},
+		valueobject.LogField{Key: "url.path", Value: c.Request.URL.Path},
+		valueobject.LogField{Key: "client.address", Value: c.ClientIP()},
+		valueobject.LogField{Key: "http.request.header.x-request-id", Value: c.GetHeader(valueobject.RequestIDHeader)},
+	)
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+
+	status := c.Writer.Status()
+	span.SetAttributes(valueobject.LogField{Key: "http.response.status_code", Value: status})
+	if status >= http.StatusInternalServerError {
+		span.RecordError(fmt.Errorf("request failed with status %d", status))
+	}
+}
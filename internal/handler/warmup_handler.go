@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+// WarmupHandler starts cache warm-up runs and reports their progress.
+type WarmupHandler struct {
+	warmupUseCase contract.IWarmupUseCase
+}
+
+func NewWarmupHandler(warmupUC contract.IWarmupUseCase) *WarmupHandler {
+	return &WarmupHandler{warmupUseCase: warmupUC}
+}
+
+// Start warms the cache from the configured sources. A text/plain body may list further
+// URLs or paths, one per line.
+func (h *WarmupHandler) Start(c *gin.Context) {
+	var extra []string
+	scanner := bufio.NewScanner(http.MaxBytesReader(c.Writer, c.Request.Body, 1<<20))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			extra = append(extra, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read URL list", "details": err.Error()})
+		return
+	}
+	// the run outlives this request but keeps its request ID for logging
+	if !h.warmupUseCase.Start(context.WithoutCancel(c.Request.Context()), "admin", extra) {
+		c.JSON(http.StatusConflict, gin.H{"error": "a warm-up run is already in progress", "progress": warmupJSON(h.warmupUseCase.Progress())})
+		return
+	}
+	c.JSON(http.StatusAccepted, warmupJSON(h.warmupUseCase.Progress()))
+}
+
+// Progress reports the current or last warm-up run.
+func (h *WarmupHandler) Progress(c *gin.Context) {
+	c.JSON(http.StatusOK, warmupJSON(h.warmupUseCase.Progress()))
+}
+
+func warmupJSON(p entity.WarmupProgress) gin.H {
+	sources := make([]gin.H, 0, len(p.Sources))
+	for _, s := range p.Sources {
+		source := gin.H{"name": s.Name, "urls": s.URLs}
+		if s.Error != "" {
+			source["error"] = s.Error
+		}
+		sources = append(sources, source)
+	}
+	percent := 0.0
+	if p.Total > 0 {
+		percent = float64(p.Done) / float64(p.Total) * 100
+	}
+	return gin.H{
+		"running":        p.Running,
+		"trigger":        p.Trigger,
+		"started_at":     timeOrNil(p.StartedAt),
+		"finished_at":    timeOrNil(p.FinishedAt),
+		"sources":        sources,
+		"total":          p.Total,
+		"done":           p.Done,
+		"percent":        percent,
+		"already_cached": p.AlreadyCached,
+		"stored":         p.Stored,
+		"uncacheable":    p.Uncacheable,
+		"failed":         p.Failed,
+	}
+}
+
+func timeOrNil(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
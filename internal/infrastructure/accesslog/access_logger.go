@@ -0,0 +1,90 @@
+package accesslog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// AccessLogger formats entries and writes them to stdout or a rotating file, independently
+// of the application log.
+type AccessLogger struct {
+	mu     sync.Mutex
+	out    io.Writer
+	format formatter
+	rotate *lumberjack.Logger
+	stop   chan struct{}
+}
+
+// NewAccessLogger builds the sink described by cfg. When access logging is disabled the
+// returned logger discards entries.
+func NewAccessLogger(cfg config.AccessLogConfig) (contract.IAccessLogger, error) {
+	if !cfg.Enabled {
+		return &AccessLogger{out: io.Discard, format: nil}, nil
+	}
+	format, err := newFormatter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Path == "" || cfg.Path == "stdout" {
+		return &AccessLogger{out: os.Stdout, format: format}, nil
+	}
+
+	rotate := &lumberjack.Logger{
+		Filename:   cfg.Path,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAgeDays,
+		Compress:   cfg.Compress,
+		LocalTime:  true,
+	}
+	logger := &AccessLogger{out: rotate, format: format, rotate: rotate, stop: make(chan struct{})}
+	if cfg.RotateIntervalSeconds > 0 {
+		go logger.rotateEvery(time.Duration(cfg.RotateIntervalSeconds) * time.Second)
+	}
+	return logger, nil
+}
+
+func (l *AccessLogger) Log(entry entity.AccessLogEntry) {
+	if l.format == nil {
+		return
+	}
+	line := l.format(entry)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.out.Write(line); err != nil {
+		fmt.Fprintf(os.Stderr, "access log write failed: %v\n", err)
+	}
+}
+
+// Close stops time based rotation and closes the log file.
+func (l *AccessLogger) Close() error {
+	if l.rotate == nil {
+		return nil
+	}
+	close(l.stop)
+	return l.rotate.Close()
+}
+
+// rotateEvery starts a new file every interval on top of lumberjack's size based rotation.
+func (l *AccessLogger) rotateEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.rotate.Rotate(); err != nil {
+				fmt.Fprintf(os.Stderr, "access log rotation failed: %v\n", err)
+			}
+		case <-l.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+// clfTimeLayout is the timestamp layout of the Apache Common Log Format.
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+type formatter func(entry entity.AccessLogEntry) []byte
+
+func newFormatter(cfg config.AccessLogConfig) (formatter, error) {
+	switch cfg.Format {
+	case "common":
+		return func(e entity.AccessLogEntry) []byte { return commonLine(e, false, cfg.Extended) }, nil
+	case "combined", "":
+		return func(e entity.AccessLogEntry) []byte { return commonLine(e, true, cfg.Extended) }, nil
+	case "json":
+		return jsonLine, nil
+	case "logfmt":
+		return logfmtLine, nil
+	case "custom":
+		return templateFormatter(cfg.Template)
+	default:
+		return nil, fmt.Errorf("unknown access log format %q", cfg.Format)
+	}
+}
+
+// commonLine renders the Apache Common Log Format, or the Combined format when combined is
+// set. Extended lines carry the cache and upstream fields in nginx style.
+func commonLine(e entity.AccessLogEntry, combined bool, extended bool) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s - - [%s] \"%s %s %s\" %d %s",
+		dash(e.ClientIP), e.Time.Format(clfTimeLayout), e.Method, e.URI, e.Proto, e.Status, clfBytes(e.Bytes))
+	if combined {
+		fmt.Fprintf(&b, " %s %s", quoted(e.Referer), quoted(e.UserAgent))
+	}
+	if extended {
+		fmt.Fprintf(&b, " cache=%s upstream=%s upstream_time=%s upstream_bytes=%s request_time=%.3f request_id=%s",
+			dash(string(e.CacheStatus)), dash(e.UpstreamAddr), upstreamSeconds(e), upstreamBytes(e), e.Duration.Seconds(), dash(e.RequestID))
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+type jsonEntry struct {
+	Time           string  `json:"time"`
+	RequestID      string  `json:"request_id,omitempty"`
+	ClientIP       string  `json:"client_ip"`
+	Method         string  `json:"method"`
+	URI            string  `json:"uri"`
+	Proto          string  `json:"proto"`
+	Route          string  `json:"route,omitempty"`
+	Status         int     `json:"status"`
+	Bytes          int     `json:"bytes"`
+	Referer        string  `json:"referer,omitempty"`
+	UserAgent      string  `json:"user_agent,omitempty"`
+	DurationMs     float64 `json:"duration_ms"`
+	CacheStatus    string  `json:"cache_status"`
+	UpstreamAddr   string  `json:"upstream_addr,omitempty"`
+	UpstreamTimeMs float64 `json:"upstream_time_ms,omitempty"`
+	UpstreamBytes  int     `json:"upstream_bytes,omitempty"`
+}
+
+func jsonLine(e entity.AccessLogEntry) []byte {
+	line, err := json.Marshal(jsonEntry{
+		Time:           e.Time.Format(time.RFC3339Nano),
+		RequestID:      e.RequestID,
+		ClientIP:       e.ClientIP,
+		Method:         e.Method,
+		URI:            e.URI,
+		Proto:          e.Proto,
+		Route:          e.Route,
+		Status:         e.Status,
+		Bytes:          e.Bytes,
+		Referer:        e.Referer,
+		UserAgent:      e.UserAgent,
+		DurationMs:     millis(e.Duration),
+		CacheStatus:    string(e.CacheStatus),
+		UpstreamAddr:   e.UpstreamAddr,
+		UpstreamTimeMs: millis(e.UpstreamTime),
+		UpstreamBytes:  e.UpstreamBytes,
+	})
+	if err != nil {
+		return []byte(fmt.Sprintf("{\"error\":%q}\n", err.Error()))
+	}
+	return append(line, '\n')
+}
+
+func logfmtLine(e entity.AccessLogEntry) []byte {
+	fields := []struct {
+		key   string
+		value string
+	}{
+		{"time", e.Time.Format(time.RFC3339Nano)},
+		{"request_id", e.RequestID},
+		{"client_ip", e.ClientIP},
+		{"method", e.Method},
+		{"uri", e.URI},
+		{"proto", e.Proto},
+		{"route", e.Route},
+		{"status", strconv.Itoa(e.Status)},
+		{"bytes", strconv.Itoa(e.Bytes)},
+		{"referer", e.Referer},
+		{"user_agent", e.UserAgent},
+		{"duration_ms", strconv.FormatFloat(millis(e.Duration), 'f', 3, 64)},
+		{"cache_status", string(e.CacheStatus)},
+		{"upstream_addr", e.UpstreamAddr},
+		{"upstream_time_ms", strconv.FormatFloat(millis(e.UpstreamTime), 'f', 3, 64)},
+		{"upstream_bytes", strconv.Itoa(e.UpstreamBytes)},
+	}
+	var b bytes.Buffer
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f.key)
+		b.WriteByte('=')
+		if f.value == "" || strings.ContainsAny(f.value, " \"=\\") {
+			b.WriteString(strconv.Quote(f.value))
+		} else {
+			b.WriteString(f.value)
+		}
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+// templateFormatter renders entries with a user supplied text/template, for example
+// `{{.ClientIP}} {{.Status}} {{.CacheStatus}} {{.UpstreamTime}}`.
+func templateFormatter(text string) (formatter, error) {
+	if text == "" {
+		return nil, fmt.Errorf("custom access log format requires access_log.template")
+	}
+	tmpl, err := template.New("access_log").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access log template: %w", err)
+	}
+	return func(e entity.AccessLogEntry) []byte {
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, e); err != nil {
+			b.Reset()
+			fmt.Fprintf(&b, "access log template error: %v", err)
+		}
+		if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+			b.WriteByte('\n')
+		}
+		return b.Bytes()
+	}, nil
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func quoted(s string) string {
+	if s == "" {
+		return "\"-\""
+	}
+	return strconv.Quote(s)
+}
+
+func clfBytes(n int) string {
+	if n <= 0 {
+		return "-"
+	}
+	return strconv.Itoa(n)
+}
+
+func upstreamSeconds(e entity.AccessLogEntry) string {
+	if e.UpstreamAddr == "" && e.UpstreamTime == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(e.UpstreamTime.Seconds(), 'f', 3, 64)
+}
+
+func upstreamBytes(e entity.AccessLogEntry) string {
+	if e.UpstreamAddr == "" && e.UpstreamTime == 0 {
+		return "-"
+	}
+	return strconv.Itoa(e.UpstreamBytes)
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
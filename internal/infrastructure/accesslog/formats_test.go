@@ -0,0 +1,103 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+func testEntry() entity.AccessLogEntry {
+	return entity.AccessLogEntry{
+		Time:          time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC),
+		RequestID:     "req-1",
+		ClientIP:      "203.0.113.7",
+		Method:        "GET",
+		URI:           "/api/v1/proxy/items",
+		Proto:         "HTTP/1.1",
+		Status:        200,
+		Bytes:         120,
+		UserAgent:     "curl/8.0",
+		Duration:      15 * time.Millisecond,
+		CacheStatus:   valueobject.CacheOutcomeMiss,
+		UpstreamAddr:  "10.0.0.5:80",
+		UpstreamTime:  12 * time.Millisecond,
+		UpstreamBytes: 480,
+	}
+}
+
+func format(t *testing.T, cfg config.AccessLogConfig, e entity.AccessLogEntry) string {
+	t.Helper()
+	f, err := newFormatter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(f(e))
+}
+
+func TestFormatsIncludeUpstreamBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.AccessLogConfig
+		want string
+	}{
+		{
+			name: "extended common",
+			cfg:  config.AccessLogConfig{Format: "common", Extended: true},
+			want: `203.0.113.7 - - [01/Mar/2025:12:00:00 +0000] "GET /api/v1/proxy/items HTTP/1.1" 200 120 cache=miss upstream=10.0.0.5:80 upstream_time=0.012 upstream_bytes=480 request_time=0.015 request_id=req-1` + "\n",
+		},
+		{
+			name: "extended combined",
+			cfg:  config.AccessLogConfig{Format: "combined", Extended: true},
+			want: `203.0.113.7 - - [01/Mar/2025:12:00:00 +0000] "GET /api/v1/proxy/items HTTP/1.1" 200 120 "-" "curl/8.0" cache=miss upstream=10.0.0.5:80 upstream_time=0.012 upstream_bytes=480 request_time=0.015 request_id=req-1` + "\n",
+		},
+		{
+			name: "plain common",
+			cfg:  config.AccessLogConfig{Format: "common"},
+			want: `203.0.113.7 - - [01/Mar/2025:12:00:00 +0000] "GET /api/v1/proxy/items HTTP/1.1" 200 120` + "\n",
+		},
+		{
+			name: "custom template",
+			cfg:  config.AccessLogConfig{Format: "custom", Template: "{{.Bytes}} {{.UpstreamBytes}}"},
+			want: "120 480\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := format(t, tt.cfg, testEntry()); got != tt.want {
+				t.Fatalf("line = %q\nwant   %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendedFormatWithoutUpstream(t *testing.T) {
+	e := testEntry()
+	e.CacheStatus = valueobject.CacheOutcomeHit
+	e.UpstreamAddr, e.UpstreamTime, e.UpstreamBytes = "", 0, 0
+	got := format(t, config.AccessLogConfig{Format: "common", Extended: true}, e)
+	if !strings.Contains(got, " upstream=- upstream_time=- upstream_bytes=- ") {
+		t.Fatalf("line = %q, want dashes for the upstream fields", got)
+	}
+}
+
+func TestJSONFormatIncludesUpstreamBytes(t *testing.T) {
+	var got map[string]any
+	if err := json.Unmarshal([]byte(format(t, config.AccessLogConfig{Format: "json"}, testEntry())), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["upstream_bytes"] != float64(480) || got["bytes"] != float64(120) {
+		t.Fatalf("upstream_bytes = %v, bytes = %v, want 480 and 120", got["upstream_bytes"], got["bytes"])
+	}
+}
+
+func TestLogfmtFormatIncludesUpstreamBytes(t *testing.T) {
+	got := format(t, config.AccessLogConfig{Format: "logfmt"}, testEntry())
+	if !strings.Contains(got, " upstream_bytes=480\n") || !strings.Contains(got, " bytes=120 ") {
+		t.Fatalf("line = %q, want bytes=120 and upstream_bytes=480", got)
+	}
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"context"
+	"os"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// AuditLogger writes audit events as JSON lines, separate from the application log so the
+// trail can be retained and shipped on its own.
+type AuditLogger struct {
+	logger *zap.Logger
+}
+
+// NewAuditLogger writes to path, rotated by size, or to stdout when path is empty.
+func NewAuditLogger(path string) contract.IAuditLogger {
+	var sink zapcore.WriteSyncer = zapcore.AddSync(os.Stdout)
+	if path != "" {
+		sink = zapcore.AddSync(&lumberjack.Logger{Filename: path, MaxSize: 100, MaxBackups: 10, Compress: true})
+	}
+	encoderConfig := zap.NewProductionEncoderConfig()
+	// the event carries its own timestamp: when the call was received
+	encoderConfig.TimeKey = ""
+	encoderConfig.MessageKey = "event"
+	encoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), sink, zapcore.InfoLevel)
+	return &AuditLogger{logger: zap.New(core)}
+}
+
+func (a *AuditLogger) Record(ctx context.Context, event entity.AuditEvent) {
+	fields := []zap.Field{
+		zap.Time("time", event.Time),
+		zap.String("actor", event.Actor),
+		zap.String("remote_addr", event.RemoteAddr),
+		zap.String("request_id", event.RequestID),
+		zap.String("method", event.Method),
+		zap.String("path", event.Path),
+		zap.String("query", event.Query),
+		zap.Int("status", event.Status),
+		zap.Bool("allowed", event.Allowed),
+	}
+	if event.DenyReason != "" {
+		fields = append(fields, zap.String("deny_reason", event.DenyReason))
+	}
+	a.logger.Info("admin_action", fields...)
+}
+
+func (a *AuditLogger) Close() error {
+	_ = a.logger.Sync()
+	return nil
+}
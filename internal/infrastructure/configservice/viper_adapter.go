@@ -19,8 +19,60 @@ func (v *ViperAdapter) Load() (config.Config, error) {
 	var cfg config.Config
 	// system default values
 	viper.SetDefault("server.port", "8080")
+	viper.SetDefault("server.read_header_timeout_seconds", 10)
+	viper.SetDefault("server.read_timeout_seconds", 60)
+	viper.SetDefault("server.write_timeout_seconds", 90)
+	viper.SetDefault("server.idle_timeout_seconds", 120)
+	viper.SetDefault("server.max_header_bytes", 1<<20)
 	viper.SetDefault("cache.max_cost", "100MB")
 	viper.SetDefault("cache.num_counters", 1e6)
+	viper.SetDefault("cache.policy.heuristic_fraction", 0.1)
+	viper.SetDefault("cache.policy.heuristic_max_ttl_seconds", 86400)
+	viper.SetDefault("cache.policy.backoff_on_429", true)
+	viper.SetDefault("cache.policy.set_cookie_mode", "skip")
+	viper.SetDefault("cache.policy.max_retry_after_seconds", 300)
+	viper.SetDefault("cache.policy.stale_if_overloaded", true)
+	viper.SetDefault("cache.key.ignore_trailing_slash", true)
+	viper.SetDefault("cache.key.query_denylist", []string{"utm_*"})
+	viper.SetDefault("tracing.exporter", "otlp")
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.service_name", "revprox")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("access_log.enabled", true)
+	viper.SetDefault("access_log.format", "combined")
+	viper.SetDefault("access_log.max_size_mb", 100)
+	viper.SetDefault("access_log.max_backups", 7)
+	viper.SetDefault("access_log.compress", true)
+	viper.SetDefault("hot_keys.capacity", 100)
+	viper.SetDefault("hot_keys.sketch_width", 4096)
+	viper.SetDefault("hot_keys.sketch_depth", 4)
+	viper.SetDefault("hot_keys.decay_seconds", 300)
+	viper.SetDefault("hot_keys.prometheus_top", 10)
+	viper.SetDefault("admin.listen", "127.0.0.1:9091")
+	viper.SetDefault("admin.allowed_cidrs", []string{"127.0.0.0/8", "::1/128"})
+	viper.SetDefault("warmup.concurrency", 4)
+	viper.SetDefault("warmup.rate_per_second", 10)
+	viper.SetDefault("rate_limit.api_key_header", "X-API-Key")
+	viper.SetDefault("rate_limit.default.key", "ip")
+	viper.SetDefault("rate_limit.default.window_seconds", 1)
+	viper.SetDefault("origin.concurrency.max_queue", 100)
+	viper.SetDefault("origin.concurrency.queue_timeout_ms", 1000)
+	viper.SetDefault("origin.retry.max_attempts", 1)
+	viper.SetDefault("origin.retry.base_delay_ms", 50)
+	viper.SetDefault("origin.retry.max_delay_ms", 1000)
+	viper.SetDefault("origin.retry.statuses", []int{502, 503, 504})
+	viper.SetDefault("origin.retry.budget_ratio", 0.1)
+	viper.SetDefault("origin.retry.budget_min_per_second", 10)
+	viper.SetDefault("origin.timeouts.dial_ms", 5000)
+	viper.SetDefault("origin.timeouts.tls_handshake_ms", 5000)
+	viper.SetDefault("origin.timeouts.response_header_ms", 15000)
+	viper.SetDefault("origin.timeouts.total_ms", 30000)
+	viper.SetDefault("origin.timeouts.health_check_ms", 5000)
+	viper.SetDefault("origin.transport.max_idle_conns", 256)
+	viper.SetDefault("origin.transport.max_idle_conns_per_host", 64)
+	viper.SetDefault("origin.transport.idle_conn_timeout_seconds", 90)
+	viper.SetDefault("origin.transport.http2", true)
 	viper.SetDefault("origin.base_url", "http://localhost:3000")
 
 	// read from config file
@@ -40,5 +92,8 @@ func (v *ViperAdapter) Load() (config.Config, error) {
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return cfg, err
 	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
 	return cfg, nil
 }
\ No newline at end of file
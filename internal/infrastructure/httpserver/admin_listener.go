@@ -0,0 +1,76 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/mikiasgoitom/RevProx/internal/config"
+)
+
+const unixPrefix = "unix:"
+
+// NewAdminListener opens the admin listener described by cfg: a TCP address or a Unix
+// socket, optionally wrapped in TLS that requires client certificates when a client CA is
+// configured. Config.Validate has already rejected a client CA without a certificate.
+func NewAdminListener(cfg config.AdminConfig) (net.Listener, error) {
+	listener, err := listen(cfg.Listen)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.CertFile == "" && cfg.KeyFile == "" {
+		return listener, nil
+	}
+	tlsConfig, err := adminTLSConfig(cfg)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return tls.NewListener(listener, tlsConfig), nil
+}
+
+func listen(address string) (net.Listener, error) {
+	socketPath, isUnix := strings.CutPrefix(address, unixPrefix)
+	if !isUnix {
+		return net.Listen("tcp", address)
+	}
+	// a socket file left behind by a previous run would make the bind fail
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale admin socket: %w", err)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socketPath, 0o660); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict admin socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+func adminTLSConfig(cfg config.AdminConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load admin TLS key pair: %w", err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read admin client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in admin client CA file %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/mikiasgoitom/RevProx/internal/contract"
 	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -28,27 +29,44 @@ func NewZapAdapter(isProduction bool) (contract.ILogger, error) {
 	return &ZapAdapter{logger: zapLogger}, nil
 }
 
-func (z *ZapAdapter) toZapFields(fields ...valueobject.LogField) []zap.Field {
-	zapFields := make([]zap.Field, len(fields))
-	for i, field := range fields {
-		zapFields[i] = zap.Any(field.Key, field.Value)
-	}	
+// toZapFields converts fields and prepends the request ID, trace ID, client IP and route
+// found in ctx so every line logged while serving a request can be correlated.
+func (z *ZapAdapter) toZapFields(ctx context.Context, fields ...valueobject.LogField) []zap.Field {
+	zapFields := make([]zap.Field, 0, len(fields)+5)
+	if info, ok := valueobject.RequestInfoFromContext(ctx); ok {
+		zapFields = append(zapFields,
+			zap.String("request_id", info.RequestID),
+			zap.String("client_ip", info.ClientIP),
+			zap.String("route", info.Route),
+		)
+	}
+	if ctx != nil {
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			zapFields = append(zapFields,
+				zap.String("trace_id", spanContext.TraceID().String()),
+				zap.String("span_id", spanContext.SpanID().String()),
+			)
+		}
+	}
+	for _, field := range fields {
+		zapFields = append(zapFields, zap.Any(field.Key, field.Value))
+	}
 	return zapFields
 }
 
 func (z *ZapAdapter) Info(ctx context.Context, msg string, fields ...valueobject.LogField){
-	z.logger.Info(msg, z.toZapFields(fields...)...)
+	z.logger.Info(msg, z.toZapFields(ctx, fields...)...)
 }
 func (z *ZapAdapter) Debug(ctx context.Context, msg string, fields ...valueobject.LogField){
-	z.logger.Debug(msg, z.toZapFields(fields...)...)
+	z.logger.Debug(msg, z.toZapFields(ctx, fields...)...)
 }
 
 func (z *ZapAdapter) Warn(ctx context.Context, msg string, fields ...valueobject.LogField){
-	z.logger.Warn(msg, z.toZapFields(fields...)...)
+	z.logger.Warn(msg, z.toZapFields(ctx, fields...)...)
 }
 func (z *ZapAdapter) Error(ctx context.Context, msg string, fields ...valueobject.LogField){
-	z.logger.Error(msg, z.toZapFields(fields...)...)
+	z.logger.Error(msg, z.toZapFields(ctx, fields...)...)
 }
 func (z *ZapAdapter) Fatal(ctx context.Context, msg string, fields ...valueobject.LogField){
-	z.logger.Fatal(msg, z.toZapFields(fields...)...)
+	z.logger.Fatal(msg, z.toZapFields(ctx, fields...)...)
 }
\ No newline at end of file
@@ -0,0 +1,60 @@
+package metricsadapter
+
+import (
+	"strconv"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// hotKeyCollector exports one gauge per ranking dimension, labelled with the subject
+// (cache key or client IP) and its rank.
+type hotKeyCollector struct {
+	tracker contract.IHotKeyTracker
+	top     int
+	descs   map[valueobject.HotKeyDimension]*prometheus.Desc
+}
+
+func newHotKeyCollector(subject string, tracker contract.IHotKeyTracker, top int) *hotKeyCollector {
+	labels := []string{subject, "rank"}
+	name := "caching_proxy_hot_" + subject + "_"
+	return &hotKeyCollector{
+		tracker: tracker,
+		top:     top,
+		descs: map[valueobject.HotKeyDimension]*prometheus.Desc{
+			valueobject.HotKeyByRequests:   prometheus.NewDesc(name+"requests", "Estimated requests of the top "+subject+"s by request count.", labels, nil),
+			valueobject.HotKeyByMisses:     prometheus.NewDesc(name+"misses", "Estimated cache misses of the top "+subject+"s by miss count.", labels, nil),
+			valueobject.HotKeyByBytes:      prometheus.NewDesc(name+"bytes", "Estimated bytes served to the top "+subject+"s by bytes.", labels, nil),
+			valueobject.HotKeyByOriginTime: prometheus.NewDesc(name+"origin_seconds", "Estimated origin time spent on the top "+subject+"s by origin time.", labels, nil),
+		},
+	}
+}
+
+func (c *hotKeyCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range c.descs {
+		ch <- desc
+	}
+}
+
+func (c *hotKeyCollector) Collect(ch chan<- prometheus.Metric) {
+	for dim, desc := range c.descs {
+		for i, hot := range c.tracker.Top(dim, c.top) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, hotKeyValue(hot, dim), hot.Key, strconv.Itoa(i+1))
+		}
+	}
+}
+
+func hotKeyValue(hot entity.HotKey, dim valueobject.HotKeyDimension) float64 {
+	switch dim {
+	case valueobject.HotKeyByMisses:
+		return float64(hot.Misses)
+	case valueobject.HotKeyByBytes:
+		return float64(hot.Bytes)
+	case valueobject.HotKeyByOriginTime:
+		return hot.OriginTime.Seconds()
+	default:
+		return float64(hot.Requests)
+	}
+}
@@ -0,0 +1,89 @@
+package metricsadapter
+
+import (
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+// histogramBounds mirror latencyBuckets: 100µs doubling up to ~13s.
+var histogramBounds = func() []time.Duration {
+	bounds := make([]time.Duration, len(latencyBuckets))
+	for i, b := range latencyBuckets {
+		bounds[i] = time.Duration(b * float64(time.Second))
+	}
+	return bounds
+}()
+
+// latencyHistogram is a fixed-bucket histogram; the last bucket catches everything above
+// the largest bound.
+type latencyHistogram struct {
+	counts [latencyBucketCount + 1]uint64
+	count  uint64
+	max    time.Duration
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	i := 0
+	for i < len(histogramBounds) && d > histogramBounds[i] {
+		i++
+	}
+	h.counts[i]++
+	h.count++
+	if d > h.max {
+		h.max = d
+	}
+}
+
+func (h *latencyHistogram) merge(other *latencyHistogram) {
+	for i, n := range other.counts {
+		h.counts[i] += n
+	}
+	h.count += other.count
+	if other.max > h.max {
+		h.max = other.max
+	}
+}
+
+func (h *latencyHistogram) summary() entity.LatencySummary {
+	return entity.LatencySummary{
+		Count: h.count,
+		P50:   h.quantile(0.50),
+		P90:   h.quantile(0.90),
+		P95:   h.quantile(0.95),
+		P99:   h.quantile(0.99),
+		Max:   h.max,
+	}
+}
+
+// quantile estimates q by interpolating linearly inside the bucket that holds it.
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := q * float64(h.count)
+	var cumulative uint64
+	for i, n := range h.counts {
+		if n == 0 || float64(cumulative+n) < rank {
+			cumulative += n
+			continue
+		}
+		if i == len(histogramBounds) {
+			return h.max
+		}
+		lower := time.Duration(0)
+		if i > 0 {
+			lower = histogramBounds[i-1]
+		}
+		upper := histogramBounds[i]
+		if upper > h.max {
+			upper = h.max
+		}
+		estimate := lower + time.Duration((rank-float64(cumulative))/float64(n)*float64(upper-lower))
+		if estimate < lower {
+			estimate = lower
+		}
+		return estimate
+	}
+	return h.max
+}
@@ -0,0 +1,120 @@
+package metricsadapter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// MultiMetricsAdapter fans every recording out to several adapters, e.g. Prometheus and
+// the in-memory windows behind the admin stats endpoint.
+type MultiMetricsAdapter struct {
+	adapters []contract.IMetricsAdapter
+}
+
+func NewMultiMetricsAdapter(adapters ...contract.IMetricsAdapter) contract.IMetricsAdapter {
+	return &MultiMetricsAdapter{adapters: adapters}
+}
+
+func (m *MultiMetricsAdapter) each(record func(contract.IMetricsAdapter) error) error {
+	var errs []error
+	for _, adapter := range m.adapters {
+		if err := record(adapter); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *MultiMetricsAdapter) IncHit(ctx context.Context) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.IncHit(ctx) })
+}
+
+func (m *MultiMetricsAdapter) IncMiss(ctx context.Context) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.IncMiss(ctx) })
+}
+
+func (m *MultiMetricsAdapter) RecordEviction(ctx context.Context, reason valueobject.EvictionReason, key valueobject.CacheKey) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.RecordEviction(ctx, reason, key) })
+}
+
+func (m *MultiMetricsAdapter) RecordUpstreamLatency(ctx context.Context, d time.Duration) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.RecordUpstreamLatency(ctx, d) })
+}
+
+func (m *MultiMetricsAdapter) RecordUpstreamPhase(ctx context.Context, phase string, d time.Duration) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.RecordUpstreamPhase(ctx, phase, d) })
+}
+
+func (m *MultiMetricsAdapter) RecordCacheLatency(ctx context.Context, d time.Duration) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.RecordCacheLatency(ctx, d) })
+}
+
+func (m *MultiMetricsAdapter) RecordTotalLatency(ctx context.Context, d time.Duration) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.RecordTotalLatency(ctx, d) })
+}
+
+func (m *MultiMetricsAdapter) RecordRequest(ctx context.Context, route string, method string, status int, outcome valueobject.CacheOutcome, bytes int, d time.Duration) error {
+	return m.each(func(a contract.IMetricsAdapter) error {
+		return a.RecordRequest(ctx, route, method, status, outcome, bytes, d)
+	})
+}
+
+func (m *MultiMetricsAdapter) IncOriginInFlight(ctx context.Context) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.IncOriginInFlight(ctx) })
+}
+
+func (m *MultiMetricsAdapter) DecOriginInFlight(ctx context.Context) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.DecOriginInFlight(ctx) })
+}
+
+func (m *MultiMetricsAdapter) RecordOriginError(ctx context.Context, kind string) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.RecordOriginError(ctx, kind) })
+}
+
+func (m *MultiMetricsAdapter) RecordOriginConnection(ctx context.Context, reused bool, protocol string) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.RecordOriginConnection(ctx, reused, protocol) })
+}
+
+func (m *MultiMetricsAdapter) RecordOriginQueueWait(ctx context.Context, outcome string, d time.Duration) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.RecordOriginQueueWait(ctx, outcome, d) })
+}
+
+func (m *MultiMetricsAdapter) RecordOriginRetry(ctx context.Context, reason string, outcome string) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.RecordOriginRetry(ctx, reason, outcome) })
+}
+
+func (m *MultiMetricsAdapter) RecordOriginHedge(ctx context.Context, outcome string) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.RecordOriginHedge(ctx, outcome) })
+}
+
+func (m *MultiMetricsAdapter) RecordRateLimited(ctx context.Context, rule string, key valueobject.RateLimitKey) error {
+	return m.each(func(a contract.IMetricsAdapter) error { return a.RecordRateLimited(ctx, rule, key) })
+}
+
+func (m *MultiMetricsAdapter) ObserveCache(provider contract.ICacheStatsProvider) {
+	for _, adapter := range m.adapters {
+		adapter.ObserveCache(provider)
+	}
+}
+
+func (m *MultiMetricsAdapter) ObserveHotKeys(keys contract.IHotKeyTracker, clients contract.IHotKeyTracker, top int) {
+	for _, adapter := range m.adapters {
+		adapter.ObserveHotKeys(keys, clients, top)
+	}
+}
+
+func (m *MultiMetricsAdapter) ObserveOriginLimiter(upstream string, limiter contract.IConcurrencyLimiter) {
+	for _, adapter := range m.adapters {
+		adapter.ObserveOriginLimiter(upstream, limiter)
+	}
+}
+
+func (m *MultiMetricsAdapter) ObserveRateLimiter(limiter contract.IRateLimiter) {
+	for _, adapter := range m.adapters {
+		adapter.ObserveRateLimiter(limiter)
+	}
+}
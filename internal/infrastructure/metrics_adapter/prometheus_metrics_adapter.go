@@ -2,9 +2,15 @@ package metricsadapter
 
 import (
 	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -13,10 +19,33 @@ import (
 type PrometheusAdapter struct {
 	hits      prometheus.Counter
 	misses    prometheus.Counter
-	evictions prometheus.Counter
+	evictions *prometheus.CounterVec
 	latencies *prometheus.HistogramVec
+	phases    *prometheus.HistogramVec
+	requests  *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+	bytes     *prometheus.CounterVec
+	inFlight  prometheus.Gauge
+	originErr *prometheus.CounterVec
+	limited   *prometheus.CounterVec
+	queueWait *prometheus.HistogramVec
+	retries   *prometheus.CounterVec
+	hedges    *prometheus.CounterVec
+	conns     *prometheus.CounterVec
+
+	hotKeysOnce sync.Once
 }
 
+// requestLabels are kept to bounded value sets: route templates, known methods, status
+// classes and cache outcomes.
+var requestLabels = []string{"route", "method", "status_class", "cache"}
+
+// latencyBucketCount buckets starting at 100µs span up to ~13s so sub-millisecond cache
+// lookups are distinguishable.
+const latencyBucketCount = 18
+
+var latencyBuckets = prometheus.ExponentialBuckets(0.0001, 2, latencyBucketCount)
+
 // NewPrometheusAdapter creates and registers the Prometheus metrics.
 func NewPrometheusAdapter() contract.IMetricsAdapter {
 	return &PrometheusAdapter{
@@ -28,15 +57,62 @@ func NewPrometheusAdapter() contract.IMetricsAdapter {
 			Name: "caching_proxy_cache_misses_total",
 			Help: "The total number of cache misses.",
 		}),
-		evictions: promauto.NewCounter(prometheus.CounterOpts{
+		evictions: promauto.NewCounterVec(prometheus.CounterOpts{
 			Name: "caching_proxy_cache_evictions_total",
-			Help: "The total number of items evicted from the cache.",
-		}),
+			Help: "The total number of items that left the cache, partitioned by reason.",
+		}, []string{"reason"}), // Labels: "capacity", "expired", "rejected", "purged"
 		latencies: promauto.NewHistogramVec(prometheus.HistogramOpts{
 			Name:    "caching_proxy_latency_seconds",
 			Help:    "Request latency in seconds, partitioned by type.",
-			Buckets: prometheus.DefBuckets, // Default buckets are fine for now
+			Buckets: latencyBuckets,
 		}, []string{"type"}), // Labels: "total", "upstream", "cache"
+		phases: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "caching_proxy_upstream_phase_seconds",
+			Help:    "Origin request latency in seconds, partitioned by phase.",
+			Buckets: latencyBuckets,
+		}, []string{"phase"}), // Labels: "dns", "connect", "tls", "ttfb", "transfer"
+		requests: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "caching_proxy_requests_total",
+			Help: "The total number of requests served, partitioned by route, method, status class and cache outcome.",
+		}, requestLabels),
+		durations: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "caching_proxy_request_duration_seconds",
+			Help:    "End-to-end request duration in seconds, partitioned by route, method, status class and cache outcome.",
+			Buckets: latencyBuckets,
+		}, requestLabels),
+		bytes: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "caching_proxy_response_bytes_total",
+			Help: "The total number of response body bytes sent to clients, partitioned by cache outcome.",
+		}, []string{"cache"}),
+		inFlight: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "caching_proxy_origin_inflight_requests",
+			Help: "The number of requests currently in flight to the origin, including those queued for a concurrency slot.",
+		}),
+		originErr: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "caching_proxy_origin_errors_total",
+			Help: "The total number of failed origin requests, partitioned by kind.",
+		}, []string{"kind"}),
+		limited: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "caching_proxy_rate_limited_total",
+			Help: "The total number of requests rejected by rate limiting, partitioned by rule and key type.",
+		}, []string{"rule", "key"}),
+		queueWait: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "caching_proxy_origin_queue_wait_seconds",
+			Help:    "Time spent waiting for an origin concurrency slot in seconds, partitioned by outcome.",
+			Buckets: latencyBuckets,
+		}, []string{"outcome"}), // Labels: "admitted", "shed", "canceled"
+		retries: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "caching_proxy_origin_retries_total",
+			Help: "The total number of failed origin attempts considered for a retry, partitioned by reason and outcome.",
+		}, []string{"reason", "outcome"}), // Outcomes: "retried", "budget_exhausted"
+		hedges: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "caching_proxy_origin_hedges_total",
+			Help: "The total number of slow origin requests considered for hedging, partitioned by outcome.",
+		}, []string{"outcome"}), // Labels: "won", "lost", "budget_exhausted"
+		conns: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "caching_proxy_origin_connections_total",
+			Help: "The total number of origin responses, partitioned by whether their connection was reused from the idle pool and by protocol.",
+		}, []string{"state", "protocol"}), // States: "reused", "new"
 	}
 }
 
@@ -50,8 +126,8 @@ func (a *PrometheusAdapter) IncMiss(ctx context.Context) error {
 	return nil
 }
 
-func (a *PrometheusAdapter) RecordEviction(ctx context.Context) error {
-	a.evictions.Inc()
+func (a *PrometheusAdapter) RecordEviction(ctx context.Context, reason valueobject.EvictionReason, key valueobject.CacheKey) error {
+	a.evictions.WithLabelValues(string(reason)).Inc()
 	return nil
 }
 
@@ -60,6 +136,11 @@ func (a *PrometheusAdapter) RecordUpstreamLatency(ctx context.Context, d time.Du
 	return nil
 }
 
+func (a *PrometheusAdapter) RecordUpstreamPhase(ctx context.Context, phase string, d time.Duration) error {
+	a.phases.WithLabelValues(phase).Observe(d.Seconds())
+	return nil
+}
+
 func (a *PrometheusAdapter) RecordCacheLatency(ctx context.Context, d time.Duration) error {
 	a.latencies.WithLabelValues("cache").Observe(d.Seconds())
 	return nil
@@ -69,3 +150,152 @@ func (a *PrometheusAdapter) RecordTotalLatency(ctx context.Context, d time.Durat
 	a.latencies.WithLabelValues("total").Observe(d.Seconds())
 	return nil
 }
+
+func (a *PrometheusAdapter) RecordRequest(ctx context.Context, route string, method string, status int, outcome valueobject.CacheOutcome, bytes int, d time.Duration) error {
+	labels := prometheus.Labels{
+		"route":        route,
+		"method":       methodLabel(method),
+		"status_class": statusClass(status),
+		"cache":        string(outcome),
+	}
+	a.requests.With(labels).Inc()
+	a.durations.With(labels).Observe(d.Seconds())
+	if bytes > 0 {
+		a.bytes.WithLabelValues(string(outcome)).Add(float64(bytes))
+	}
+	return nil
+}
+
+func (a *PrometheusAdapter) IncOriginInFlight(ctx context.Context) error {
+	a.inFlight.Inc()
+	return nil
+}
+
+func (a *PrometheusAdapter) DecOriginInFlight(ctx context.Context) error {
+	a.inFlight.Dec()
+	return nil
+}
+
+func (a *PrometheusAdapter) RecordOriginError(ctx context.Context, kind string) error {
+	a.originErr.WithLabelValues(kind).Inc()
+	return nil
+}
+
+func (a *PrometheusAdapter) RecordOriginConnection(ctx context.Context, reused bool, protocol string) error {
+	state := "new"
+	if reused {
+		state = "reused"
+	}
+	a.conns.WithLabelValues(state, protocol).Inc()
+	return nil
+}
+
+func (a *PrometheusAdapter) RecordOriginQueueWait(ctx context.Context, outcome string, d time.Duration) error {
+	a.queueWait.WithLabelValues(outcome).Observe(d.Seconds())
+	return nil
+}
+
+func (a *PrometheusAdapter) RecordOriginRetry(ctx context.Context, reason string, outcome string) error {
+	a.retries.WithLabelValues(reason, outcome).Inc()
+	return nil
+}
+
+func (a *PrometheusAdapter) RecordOriginHedge(ctx context.Context, outcome string) error {
+	a.hedges.WithLabelValues(outcome).Inc()
+	return nil
+}
+
+func (a *PrometheusAdapter) RecordRateLimited(ctx context.Context, rule string, key valueobject.RateLimitKey) error {
+	a.limited.WithLabelValues(rule, string(key)).Inc()
+	return nil
+}
+
+// ObserveCache registers collectors that read the provider's stats at scrape time. It must be
+// called at most once per process since the collectors are globally registered.
+func (a *PrometheusAdapter) ObserveCache(provider contract.ICacheStatsProvider) {
+	stats := func() entity.CacheStats {
+		return provider.Stats(context.Background())
+	}
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "caching_proxy_cache_bytes",
+		Help: "The number of bytes currently held in the cache.",
+	}, func() float64 { return float64(stats().Bytes) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "caching_proxy_cache_max_bytes",
+		Help: "The configured cache capacity in bytes.",
+	}, func() float64 { return float64(stats().MaxBytes) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "caching_proxy_cache_entries",
+		Help: "The number of entries currently held in the cache.",
+	}, func() float64 { return float64(stats().Entries) })
+
+	ristrettoCounters := map[string]func(entity.CacheStats) uint64{
+		"hits":          func(s entity.CacheStats) uint64 { return s.Hits },
+		"misses":        func(s entity.CacheStats) uint64 { return s.Misses },
+		"keys_added":    func(s entity.CacheStats) uint64 { return s.KeysAdded },
+		"keys_updated":  func(s entity.CacheStats) uint64 { return s.KeysUpdated },
+		"keys_evicted":  func(s entity.CacheStats) uint64 { return s.KeysEvicted },
+		"sets_dropped":  func(s entity.CacheStats) uint64 { return s.SetsDropped },
+		"sets_rejected": func(s entity.CacheStats) uint64 { return s.SetsRejected },
+		"gets_dropped":  func(s entity.CacheStats) uint64 { return s.GetsDropped },
+		"gets_kept":     func(s entity.CacheStats) uint64 { return s.GetsKept },
+	}
+	for name, read := range ristrettoCounters {
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Name: "caching_proxy_ristretto_" + name + "_total",
+			Help: "Ristretto internal counter: " + strings.ReplaceAll(name, "_", " ") + ".",
+		}, func() float64 { return float64(read(stats())) })
+	}
+}
+
+// ObserveHotKeys registers a collector that exports the top entries of each ranking at
+// scrape time. Only the current top entries are emitted, so label cardinality stays bounded
+// by top even as the ranking changes. The collectors are registered by the first call only;
+// later calls are ignored rather than failing on the duplicate registration.
+func (a *PrometheusAdapter) ObserveHotKeys(keys contract.IHotKeyTracker, clients contract.IHotKeyTracker, top int) {
+	a.hotKeysOnce.Do(func() {
+		prometheus.MustRegister(newHotKeyCollector("key", keys, top), newHotKeyCollector("client", clients, top))
+	})
+}
+
+// ObserveOriginLimiter registers gauges that read the limiter's state at scrape time,
+// labelled with the upstream they protect.
+func (a *PrometheusAdapter) ObserveOriginLimiter(upstream string, limiter contract.IConcurrencyLimiter) {
+	labels := prometheus.Labels{"upstream": upstream}
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "caching_proxy_origin_concurrency_limit",
+		Help:        "The current limit on concurrent origin requests.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(limiter.Stats().Limit) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "caching_proxy_origin_queue_depth",
+		Help:        "The number of requests waiting for an origin concurrency slot.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(limiter.Stats().Queued) })
+}
+
+// ObserveRateLimiter registers a gauge of the buckets the limiter currently tracks.
+func (a *PrometheusAdapter) ObserveRateLimiter(limiter contract.IRateLimiter) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "caching_proxy_rate_limit_buckets",
+		Help: "The number of rate limit buckets currently held in memory.",
+	}, func() float64 { return float64(limiter.Len()) })
+}
+
+// methodLabel folds non-standard methods together to bound label cardinality.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
@@ -0,0 +1,336 @@
+package metricsadapter
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+const (
+	// slotWidth is the resolution of the sliding windows; retention is the longest window.
+	slotWidth = 5 * time.Second
+	retention = time.Hour
+	slotCount = int(retention / slotWidth)
+
+	// maxPrefixesPerSlot bounds the eviction prefix map of one slot; the rest is folded
+	// into otherPrefix.
+	maxPrefixesPerSlot = 256
+	otherPrefix        = "(other)"
+	topPrefixes        = 10
+)
+
+// WindowStatsAdapter keeps an in-memory ring of per-slot counters and latency histograms so
+// hit ratios, error rates and percentiles can be reported over sliding windows of up to an
+// hour without an external metrics backend.
+type WindowStatsAdapter struct {
+	mu          sync.Mutex
+	timeService contract.ITimeService
+	slots       [slotCount]statsSlot
+}
+
+type statsSlot struct {
+	id              int64
+	requests        uint64
+	hits            uint64
+	misses          uint64
+	bytesServed     uint64
+	bytesFromCache  uint64
+	evictions       uint64
+	originRequests  uint64
+	originErrors    map[string]uint64
+	rateLimited     uint64
+	originRetries   uint64
+	connsReused     uint64
+	connsNew        uint64
+	evictedPrefixes map[string]uint64
+	upstream        latencyHistogram
+	cache           latencyHistogram
+	total           latencyHistogram
+}
+
+func NewWindowStatsAdapter(timeService contract.ITimeService) *WindowStatsAdapter {
+	return &WindowStatsAdapter{timeService: timeService}
+}
+
+// slot returns the current slot, resetting it when it still holds data from a previous lap
+// of the ring. The caller must hold the lock.
+func (a *WindowStatsAdapter) slot() *statsSlot {
+	id := a.timeService.Now().UnixNano() / int64(slotWidth)
+	s := &a.slots[id%int64(slotCount)]
+	if s.id != id {
+		*s = statsSlot{id: id}
+	}
+	return s
+}
+
+func (a *WindowStatsAdapter) IncHit(ctx context.Context) error {
+	a.mu.Lock()
+	a.slot().hits++
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *WindowStatsAdapter) IncMiss(ctx context.Context) error {
+	a.mu.Lock()
+	a.slot().misses++
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *WindowStatsAdapter) RecordEviction(ctx context.Context, reason valueobject.EvictionReason, key valueobject.CacheKey) error {
+	// purges are operator actions, not cache pressure
+	if reason == valueobject.EvictionPurged {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := a.slot()
+	s.evictions++
+	if key.NormalizedURL == "" {
+		return nil
+	}
+	prefix := evictionPrefix(key)
+	if s.evictedPrefixes == nil {
+		s.evictedPrefixes = make(map[string]uint64)
+	}
+	if _, ok := s.evictedPrefixes[prefix]; !ok && len(s.evictedPrefixes) >= maxPrefixesPerSlot {
+		prefix = otherPrefix
+	}
+	s.evictedPrefixes[prefix]++
+	return nil
+}
+
+func (a *WindowStatsAdapter) RecordUpstreamLatency(ctx context.Context, d time.Duration) error {
+	a.mu.Lock()
+	a.slot().upstream.observe(d)
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *WindowStatsAdapter) RecordUpstreamPhase(ctx context.Context, phase string, d time.Duration) error {
+	return nil
+}
+
+func (a *WindowStatsAdapter) RecordCacheLatency(ctx context.Context, d time.Duration) error {
+	a.mu.Lock()
+	a.slot().cache.observe(d)
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *WindowStatsAdapter) RecordTotalLatency(ctx context.Context, d time.Duration) error {
+	a.mu.Lock()
+	a.slot().total.observe(d)
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *WindowStatsAdapter) RecordRequest(ctx context.Context, route string, method string, status int, outcome valueobject.CacheOutcome, bytes int, d time.Duration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := a.slot()
+	s.requests++
+	if outcome == valueobject.CacheOutcomeNone || bytes <= 0 {
+		return nil
+	}
+	s.bytesServed += uint64(bytes)
+	switch outcome {
+	case valueobject.CacheOutcomeHit, valueobject.CacheOutcomeStale, valueobject.CacheOutcomeRevalidated:
+		s.bytesFromCache += uint64(bytes)
+	}
+	return nil
+}
+
+// IncOriginInFlight is called once per origin request, which makes it the denominator of
+// the origin error rate.
+func (a *WindowStatsAdapter) IncOriginInFlight(ctx context.Context) error {
+	a.mu.Lock()
+	a.slot().originRequests++
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *WindowStatsAdapter) DecOriginInFlight(ctx context.Context) error {
+	return nil
+}
+
+func (a *WindowStatsAdapter) RecordOriginError(ctx context.Context, kind string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := a.slot()
+	if s.originErrors == nil {
+		s.originErrors = make(map[string]uint64)
+	}
+	s.originErrors[kind]++
+	return nil
+}
+
+func (a *WindowStatsAdapter) RecordOriginConnection(ctx context.Context, reused bool, protocol string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := a.slot()
+	if reused {
+		s.connsReused++
+	} else {
+		s.connsNew++
+	}
+	return nil
+}
+
+func (a *WindowStatsAdapter) RecordOriginQueueWait(ctx context.Context, outcome string, d time.Duration) error {
+	return nil
+}
+
+func (a *WindowStatsAdapter) RecordOriginRetry(ctx context.Context, reason string, outcome string) error {
+	if outcome != "retried" {
+		return nil
+	}
+	a.mu.Lock()
+	a.slot().originRetries++
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *WindowStatsAdapter) RecordOriginHedge(ctx context.Context, outcome string) error {
+	return nil
+}
+
+func (a *WindowStatsAdapter) RecordRateLimited(ctx context.Context, rule string, key valueobject.RateLimitKey) error {
+	a.mu.Lock()
+	a.slot().rateLimited++
+	a.mu.Unlock()
+	return nil
+}
+
+// ObserveCache is a no-op: cache size is read directly from the repository by the stats use case.
+func (a *WindowStatsAdapter) ObserveCache(provider contract.ICacheStatsProvider) {}
+
+// ObserveHotKeys is a no-op: hot keys are served by the admin endpoints directly.
+func (a *WindowStatsAdapter) ObserveHotKeys(keys contract.IHotKeyTracker, clients contract.IHotKeyTracker, top int) {
+}
+
+// ObserveOriginLimiter is a no-op: the limiter state is only exported to Prometheus.
+func (a *WindowStatsAdapter) ObserveOriginLimiter(string, contract.IConcurrencyLimiter) {}
+
+// ObserveRateLimiter is a no-op: the bucket count is only exported to Prometheus.
+func (a *WindowStatsAdapter) ObserveRateLimiter(limiter contract.IRateLimiter) {}
+
+// Snapshot aggregates the slots that fall within window, capped at the retention period.
+func (a *WindowStatsAdapter) Snapshot(window time.Duration) entity.Metrics {
+	if window > retention {
+		window = retention
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	current := a.slot().id
+	oldest := current - int64(window/slotWidth) + 1
+	var (
+		sum                    statsSlot
+		upstream, cache, total latencyHistogram
+		originErrors           = make(map[string]uint64)
+		prefixes               = make(map[string]uint64)
+	)
+	for i := range a.slots {
+		s := &a.slots[i]
+		if s.id < oldest || s.id > current {
+			continue
+		}
+		sum.requests += s.requests
+		sum.hits += s.hits
+		sum.misses += s.misses
+		sum.bytesServed += s.bytesServed
+		sum.bytesFromCache += s.bytesFromCache
+		sum.evictions += s.evictions
+		sum.originRequests += s.originRequests
+		sum.rateLimited += s.rateLimited
+		sum.originRetries += s.originRetries
+		sum.connsReused += s.connsReused
+		sum.connsNew += s.connsNew
+		upstream.merge(&s.upstream)
+		cache.merge(&s.cache)
+		total.merge(&s.total)
+		for kind, n := range s.originErrors {
+			originErrors[kind] += n
+		}
+		for prefix, n := range s.evictedPrefixes {
+			prefixes[prefix] += n
+		}
+	}
+
+	var errorCount uint64
+	for _, n := range originErrors {
+		errorCount += n
+	}
+	return entity.Metrics{
+		Window:             window,
+		Requests:           sum.requests,
+		Hits:               sum.hits,
+		Misses:             sum.misses,
+		HitRatio:           ratio(sum.hits, sum.hits+sum.misses),
+		BytesServed:        sum.bytesServed,
+		BytesFromCache:     sum.bytesFromCache,
+		ByteHitRatio:       ratio(sum.bytesFromCache, sum.bytesServed),
+		UpstreamLatency:    upstream.summary(),
+		CacheLatency:       cache.summary(),
+		TotalLatency:       total.summary(),
+		Evictions:          sum.evictions,
+		TopEvictedPrefixes: topPrefixCounts(prefixes, topPrefixes),
+		OriginRequests:     sum.originRequests,
+		OriginErrors:       originErrors,
+		OriginErrorRate:    ratio(errorCount, sum.originRequests),
+		OriginRetries:      sum.originRetries,
+		OriginConnsReused:  sum.connsReused,
+		OriginConnsNew:     sum.connsNew,
+		RateLimited:        sum.rateLimited,
+	}
+}
+
+// evictionPrefix groups keys by namespace and first path segment, e.g. "/images/".
+func evictionPrefix(key valueobject.CacheKey) string {
+	p := key.NormalizedURL
+	if u, err := url.Parse(p); err == nil {
+		p = u.Path
+	}
+	p = strings.TrimPrefix(p, "/")
+	if i := strings.IndexByte(p, '/'); i >= 0 {
+		p = "/" + p[:i+1]
+	} else {
+		p = "/" + p
+	}
+	if key.Namespace != "" {
+		return key.Namespace + p
+	}
+	return p
+}
+
+func topPrefixCounts(counts map[string]uint64, n int) []entity.PrefixCount {
+	out := make([]entity.PrefixCount, 0, len(counts))
+	for prefix, count := range counts {
+		out = append(out, entity.PrefixCount{Prefix: prefix, Count: count})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Prefix < out[j].Prefix
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+func ratio(part, whole uint64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/c2h5oh/datasize"
@@ -14,10 +15,13 @@ import (
 )
 
 type CacheRepository struct {
-	cache *ristretto.Cache
+	cache   *ristretto.Cache
+	index   *keyIndex
+	metrics contract.IMetricsAdapter
+	logger  contract.ILogger
 }
 
-func NewCacheRepository(cfg config.Config) (contract.ICacheRepository, error) {
+func NewCacheRepository(cfg config.Config, metrics contract.IMetricsAdapter, logger contract.ILogger) (contract.ICacheRepository, error) {
 	maxCostBytes, err := datasize.ParseString(cfg.Cache.MaxCost)
 	if err != nil {
 		return nil, fmt.Errorf("invalid cache max_cost '%s': %w", cfg.Cache.MaxCost, err)
@@ -26,36 +30,93 @@ func NewCacheRepository(cfg config.Config) (contract.ICacheRepository, error) {
 	if bufferItem <= 0 {
 		bufferItem = 64 // default buffer items
 	}
+	repo := &CacheRepository{
+		index:   newKeyIndex(),
+		metrics: metrics,
+		logger:  logger,
+	}
 	ristrettoConfig := &ristretto.Config{
 		NumCounters: cfg.Cache.NumCounters,
 		MaxCost:     int64(maxCostBytes.Bytes()),
 		BufferItems: bufferItem,
+		Metrics:     true,
+		OnEvict:     repo.onEvict,
+		OnReject:    repo.onReject,
 	}
 	cache, err := ristretto.NewCache(ristrettoConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ristretto cache: %w", err)
 	}
-	return &CacheRepository{
-		cache: cache,
-	}, nil
+	repo.cache = cache
+	return repo, nil
 
 }
 
+// metadataKeyPrefix separates per-key metadata from cached responses in the same store.
+const metadataKeyPrefix = "meta:"
+
+// storedEntry is the value kept in ristretto, by pointer, for a cached response. It carries
+// the index sequence number so that an eviction can tell which version of the key it removed.
+type storedEntry struct {
+	entry entity.CacheEntry
+	seq   uint64
+}
+
+func entryKey(key valueobject.CacheKey) string {
+	return key.String()
+}
+
 func (r *CacheRepository) Get(ctx context.Context, key valueobject.CacheKey) (entity.CacheEntry, bool, error) {
-	cacheKey := fmt.Sprintf("%s:%s", key.Method, key.NormalizedURL)
-	value, found := r.cache.Get(cacheKey)
+	cacheKey := entryKey(key)
+	stored, found, err := r.load(cacheKey)
+	if !found || err != nil {
+		return entity.CacheEntry{}, false, err
+	}
+	r.index.hit(cacheKey)
+	return stored.entry, true, nil
+}
+
+func (r *CacheRepository) Peek(ctx context.Context, key valueobject.CacheKey) (entity.CacheEntry, bool, error) {
+	stored, found := r.peek(entryKey(key))
 	if !found {
 		return entity.CacheEntry{}, false, nil
 	}
-	entry, ok := value.(entity.CacheEntry)
+	return stored.entry, true, nil
+}
+
+// load reads a response from ristretto without counting it as a hit of the key.
+func (r *CacheRepository) load(cacheKey string) (storedEntry, bool, error) {
+	value, found := r.cache.Get(cacheKey)
+	if !found {
+		return storedEntry{}, false, nil
+	}
+	stored, ok := value.(*storedEntry)
 	if !ok {
-		return entity.CacheEntry{}, false, fmt.Errorf("failed to cast cache value to CacheEntry")
+		return storedEntry{}, false, fmt.Errorf("failed to cast cache value to CacheEntry")
 	}
-	return entry, true, nil
+	return *stored, true, nil
+}
+
+// peek reads a response through the index instead of ristretto's Get, which would feed the
+// read into ristretto's hit counters and TinyLFU frequencies. GetTTL only consults the
+// store, confirming the entry is still there and unexpired.
+func (r *CacheRepository) peek(cacheKey string) (storedEntry, bool) {
+	indexed, found := r.index.get(cacheKey)
+	if !found {
+		return storedEntry{}, false
+	}
+	stored := indexed.stored.Value()
+	if stored == nil {
+		return storedEntry{}, false
+	}
+	if _, found := r.cache.GetTTL(cacheKey); !found {
+		return storedEntry{}, false
+	}
+	return *stored, true
 }
 
 func (r *CacheRepository) Set(ctx context.Context, entry entity.CacheEntry) error {
-	ttl := time.Until(time.Unix(entry.ExpiresAt, 0))
+	ttl := time.Until(time.Unix(max(entry.ExpiresAt, entry.StaleUntil), 0))
 	if ttl <= 0 {
 		return nil // Do not cache expired entries
 	}
@@ -66,10 +127,14 @@ func (r *CacheRepository) Set(ctx context.Context, entry entity.CacheEntry) erro
 	if cost == 0 {
 		cost = 1 // minimum cost
 	}
-	cacheKey := fmt.Sprintf("%s:%s", entry.Key.Method, entry.Key.NormalizedURL)
-	wasAdded := r.cache.SetWithTTL(cacheKey, entry, cost, ttl)
+	cacheKey := entryKey(entry.Key)
+	// index before handing the entry to ristretto, whose admission callbacks may fire before SetWithTTL returns
+	stored := &storedEntry{entry: entry}
+	stored.seq = r.index.put(cacheKey, stored)
+	wasAdded := r.cache.SetWithTTL(cacheKey, stored, cost, ttl)
 
 	if !wasAdded {
+		r.index.remove(cacheKey, stored.seq)
 		return fmt.Errorf("failed to add entry to cache")
 	}
 
@@ -79,6 +144,138 @@ func (r *CacheRepository) Set(ctx context.Context, entry entity.CacheEntry) erro
 
 }
 
+func (r *CacheRepository) GetMetadata(ctx context.Context, key valueobject.CacheKey) (entity.CacheMetadata, bool, error) {
+	value, found := r.cache.Get(metadataKeyPrefix + entryKey(key))
+	if !found {
+		return entity.CacheMetadata{}, false, nil
+	}
+	meta, ok := value.(entity.CacheMetadata)
+	if !ok {
+		return entity.CacheMetadata{}, false, fmt.Errorf("failed to cast cache value to CacheMetadata")
+	}
+	return meta, true, nil
+}
+
+func (r *CacheRepository) SetMetadata(ctx context.Context, meta entity.CacheMetadata) error {
+	ttl := time.Until(time.Unix(meta.ExpiresAt, 0))
+	if ttl <= 0 {
+		return nil
+	}
+	// metadata is small, so it is charged a nominal cost plus any remembered error body
+	cost := int64(1)
+	if meta.BackoffResponse != nil {
+		cost += int64(len(meta.BackoffResponse.Body))
+	}
+	if !r.cache.SetWithTTL(metadataKeyPrefix+entryKey(meta.Key), meta, cost, ttl) {
+		return fmt.Errorf("failed to add metadata to cache")
+	}
+	r.cache.Wait()
+	return nil
+}
+
+func (r *CacheRepository) Delete(ctx context.Context, key valueobject.CacheKey) (bool, error) {
+	cacheKey := entryKey(key)
+	indexed, found := r.index.get(cacheKey)
+	r.cache.Del(cacheKey)
+	r.cache.Del(metadataKeyPrefix + cacheKey)
+	// ristretto does not report deletions through OnEvict, so the index is updated here
+	r.cache.Wait()
+	if !found {
+		return false, nil
+	}
+	r.index.remove(cacheKey, indexed.seq)
+	if err := r.metrics.RecordEviction(ctx, valueobject.EvictionPurged, key); err != nil {
+		r.logger.Error(ctx, "Metrics RecordEviction error", valueobject.LogField{Key: "error", Value: err.Error()})
+	}
+	return true, nil
+}
+
+func (r *CacheRepository) List(ctx context.Context, query entity.CacheEntryQuery) entity.CacheEntryPage {
+	keys := r.index.query(query)
+	page := entity.CacheEntryPage{Total: len(keys), Entries: []entity.CacheEntryInfo{}}
+	start := sort.SearchStrings(keys, query.After)
+	if start < len(keys) && keys[start] == query.After {
+		start++
+	}
+	for _, key := range keys[start:] {
+		if query.Limit > 0 && len(page.Entries) == query.Limit {
+			page.NextCursor = page.Entries[len(page.Entries)-1].Key.String()
+			break
+		}
+		// entries evicted since the keys were collected are skipped
+		if info, ok := r.Lookup(ctx, key, query.IncludeBody); ok {
+			page.Entries = append(page.Entries, info)
+		}
+	}
+	return page
+}
+
+func (r *CacheRepository) Entries(ctx context.Context) []entity.CacheEntry {
+	keys := r.index.keys(func(string) bool { return true })
+	entries := make([]entity.CacheEntry, 0, len(keys))
+	for _, key := range keys {
+		// entries evicted since the keys were collected are skipped
+		if stored, ok := r.peek(key); ok {
+			entries = append(entries, stored.entry)
+		}
+	}
+	return entries
+}
+
+func (r *CacheRepository) Lookup(ctx context.Context, key string, includeBody bool) (entity.CacheEntryInfo, bool) {
+	indexed, found := r.index.get(key)
+	if !found {
+		return entity.CacheEntryInfo{}, false
+	}
+	stored, found := r.peek(key)
+	if !found {
+		return entity.CacheEntryInfo{}, false
+	}
+	entry := stored.entry
+	info := entity.CacheEntryInfo{
+		Key:        entry.Key,
+		Size:       int64(len(entry.Payload.Body)),
+		StoredAt:   entry.StoredAt,
+		ExpiresAt:  entry.ExpiresAt,
+		StaleUntil: entry.StaleUntil,
+		Hits:       indexed.hits.Load(),
+		Status:     entry.Payload.Status,
+		Headers:    entry.Payload.Headers.Clone(),
+	}
+	if includeBody {
+		info.Body = entry.Payload.Body
+	}
+	return info, true
+}
+
+// onEvict runs on ristretto's goroutine both for capacity evictions and for TTL cleanup;
+// only the latter carries an expiration in the past.
+func (r *CacheRepository) onEvict(item *ristretto.Item) {
+	reason := valueobject.EvictionCapacity
+	if !item.Expiration.IsZero() && !item.Expiration.After(time.Now()) {
+		reason = valueobject.EvictionExpired
+	}
+	r.forget(item.Value, reason)
+}
+
+func (r *CacheRepository) onReject(item *ristretto.Item) {
+	r.forget(item.Value, valueobject.EvictionRejected)
+}
+
+func (r *CacheRepository) forget(value interface{}, reason valueobject.EvictionReason) {
+	// ristretto items carry only key hashes, so responses are told apart from metadata
+	// ("meta:" keys) and health check probes by the type of the value; only they count
+	stored, ok := value.(*storedEntry)
+	if !ok {
+		return
+	}
+	r.index.remove(entryKey(stored.entry.Key), stored.seq)
+	ctx := context.Background()
+	if err := r.metrics.RecordEviction(ctx, reason, stored.entry.Key); err != nil {
+		r.logger.Error(ctx, "Metrics RecordEviction error", valueobject.LogField{Key: "error", Value: err.Error()})
+	}
+}
+
 func (r *CacheRepository) HealthCheck(ctx context.Context) error {
 	dummyKey := "healthcheck:key"
 	dummyValue := "healthcheck:value"
@@ -95,3 +292,21 @@ func (r *CacheRepository) HealthCheck(ctx context.Context) error {
 	}
 	return nil
 }
+
+func (r *CacheRepository) Stats(ctx context.Context) entity.CacheStats {
+	m := r.cache.Metrics
+	return entity.CacheStats{
+		Entries:      uint64(r.index.len()),
+		Bytes:        m.CostAdded() - m.CostEvicted(),
+		MaxBytes:     uint64(r.cache.MaxCost()),
+		Hits:         m.Hits(),
+		Misses:       m.Misses(),
+		KeysAdded:    m.KeysAdded(),
+		KeysUpdated:  m.KeysUpdated(),
+		KeysEvicted:  m.KeysEvicted(),
+		SetsDropped:  m.SetsDropped(),
+		SetsRejected: m.SetsRejected(),
+		GetsDropped:  m.GetsDropped(),
+		GetsKept:     m.GetsKept(),
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// evictionMetrics ignores evictions; the other metrics are not used by the cache.
+type evictionMetrics struct {
+	contract.IMetricsAdapter
+}
+
+func (evictionMetrics) RecordEviction(context.Context, valueobject.EvictionReason, valueobject.CacheKey) error {
+	return nil
+}
+
+func newTestCache(t *testing.T) *CacheRepository {
+	t.Helper()
+	cfg := config.Config{Cache: config.CacheConfig{MaxCost: "1MB", NumCounters: 1000}}
+	repo, err := NewCacheRepository(cfg, evictionMetrics{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo.(*CacheRepository)
+}
+
+func TestCacheRepositoryPeekIsSideEffectFree(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestCache(t)
+	key := valueobject.CacheKey{NormalizedURL: "/items"}
+	entry := entity.CacheEntry{
+		Key:       key,
+		Payload:   entity.ResponseModel{Status: http.StatusOK, Headers: http.Header{}, Body: []byte("items")},
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	}
+	if err := repo.Set(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		got, found, err := repo.Peek(ctx, key)
+		if err != nil || !found || string(got.Payload.Body) != "items" {
+			t.Fatalf("Peek = %q, %v, %v, want the stored entry", got.Payload.Body, found, err)
+		}
+		if _, found := repo.Lookup(ctx, key.String(), false); !found {
+			t.Fatal("Lookup did not find the stored entry")
+		}
+	}
+	info, _ := repo.Lookup(ctx, key.String(), false)
+	if info.Hits != 0 || repo.cache.Metrics.Hits() != 0 {
+		t.Fatalf("after peeking hits = %d, ristretto hits = %d, want 0 and 0", info.Hits, repo.cache.Metrics.Hits())
+	}
+
+	if _, found, err := repo.Get(ctx, key); err != nil || !found {
+		t.Fatalf("Get = %v, %v, want the stored entry", found, err)
+	}
+	info, _ = repo.Lookup(ctx, key.String(), false)
+	if info.Hits != 1 || repo.cache.Metrics.Hits() != 1 {
+		t.Fatalf("after a Get hits = %d, ristretto hits = %d, want 1 and 1", info.Hits, repo.cache.Metrics.Hits())
+	}
+
+	if _, err := repo.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := repo.Peek(ctx, key); found {
+		t.Fatal("Peek found a deleted entry")
+	}
+}
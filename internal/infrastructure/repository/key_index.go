@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"weak"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+// indexedEntry is what the key index remembers about a cached response. Ristretto stores
+// only hashes, so this is the only way to know which keys are present. The response itself
+// is only weakly referenced so that bodies are only held within ristretto's MaxCost.
+type indexedEntry struct {
+	// seq identifies the write that stored this version of the key.
+	seq uint64
+	// hits is shared by every version of the key so revalidated entries keep their count.
+	hits *atomic.Uint64
+	// stored points at the value handed to ristretto, so that it can be read without a
+	// ristretto Get counting towards its admission and eviction decisions.
+	stored weak.Pointer[storedEntry]
+}
+
+type keyIndex struct {
+	mu      sync.RWMutex
+	entries map[string]indexedEntry
+	lastSeq uint64
+}
+
+func newKeyIndex() *keyIndex {
+	return &keyIndex{entries: make(map[string]indexedEntry)}
+}
+
+// put indexes stored as a new version of key and returns the sequence number identifying it.
+func (i *keyIndex) put(key string, stored *storedEntry) uint64 {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	entry := indexedEntry{stored: weak.Make(stored)}
+	if current, ok := i.entries[key]; ok {
+		entry.hits = current.hits
+	} else {
+		entry.hits = new(atomic.Uint64)
+	}
+	i.lastSeq++
+	entry.seq = i.lastSeq
+	i.entries[key] = entry
+	return entry.seq
+}
+
+// remove forgets key unless it has been stored again since the version identified by seq.
+// Store times have a resolution of a second, too coarse to tell quick rewrites apart.
+func (i *keyIndex) remove(key string, seq uint64) {
+	i.mu.Lock()
+	if current, ok := i.entries[key]; ok && current.seq <= seq {
+		delete(i.entries, key)
+	}
+	i.mu.Unlock()
+}
+
+func (i *keyIndex) get(key string) (indexedEntry, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	entry, ok := i.entries[key]
+	return entry, ok
+}
+
+func (i *keyIndex) hit(key string) {
+	i.mu.RLock()
+	if entry, ok := i.entries[key]; ok {
+		entry.hits.Add(1)
+	}
+	i.mu.RUnlock()
+}
+
+// keys returns the indexed key strings accepted by match, in order.
+func (i *keyIndex) keys(match func(string) bool) []string {
+	i.mu.RLock()
+	keys := make([]string, 0, len(i.entries))
+	for key := range i.entries {
+		if match(key) {
+			keys = append(keys, key)
+		}
+	}
+	i.mu.RUnlock()
+	sort.Strings(keys)
+	return keys
+}
+
+// query returns the keys matching q, ordered, without applying the page bounds.
+func (i *keyIndex) query(q entity.CacheEntryQuery) []string {
+	return i.keys(func(key string) bool {
+		if !strings.HasPrefix(key, q.Prefix) {
+			return false
+		}
+		return q.Pattern == nil || q.Pattern.MatchString(key)
+	})
+}
+
+func (i *keyIndex) len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.entries)
+}
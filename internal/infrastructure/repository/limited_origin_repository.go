@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// Outcomes of waiting for an origin slot, used to label queue wait metrics.
+const (
+	queueAdmitted = "admitted"
+	queueShed     = "shed"
+	queueCanceled = "canceled"
+)
+
+// LimitedOriginRepository admits fetches to the wrapped origin through a concurrency
+// limiter so a miss storm queues in the proxy instead of piling onto the origin. Health
+// checks bypass the limiter.
+type LimitedOriginRepository struct {
+	origin      contract.IOriginRepository
+	limiter     contract.IConcurrencyLimiter
+	metrics     contract.IMetricsAdapter
+	timeService contract.ITimeService
+	logger      contract.ILogger
+}
+
+func NewLimitedOriginRepository(origin contract.IOriginRepository, limiter contract.IConcurrencyLimiter, metrics contract.IMetricsAdapter, timeService contract.ITimeService, logger contract.ILogger) contract.IOriginRepository {
+	return &LimitedOriginRepository{origin: origin, limiter: limiter, metrics: metrics, timeService: timeService, logger: logger}
+}
+
+func (r *LimitedOriginRepository) Fetch(ctx context.Context, req entity.RequestModel) (entity.ResponseModel, error) {
+	waitStart := r.timeService.Monotonic()
+	release, err := r.limiter.Acquire(ctx)
+	outcome := queueAdmitted
+	var originErr *entity.OriginError
+	if errors.As(err, &originErr) {
+		outcome = queueShed
+	} else if err != nil {
+		outcome = queueCanceled
+	}
+	if err := r.metrics.RecordOriginQueueWait(ctx, outcome, r.timeService.Since(waitStart)); err != nil {
+		r.logger.Error(ctx, "Metrics RecordOriginQueueWait error", valueobject.LogField{Key: "error", Value: err.Error()})
+	}
+	if err != nil {
+		return entity.ResponseModel{}, err
+	}
+
+	fetchStart := r.timeService.Monotonic()
+	resp, err := r.origin.Fetch(ctx, req)
+	// a canceled client says nothing about the origin's health
+	failed := (err != nil && ctx.Err() == nil) || (err == nil && resp.Status >= http.StatusInternalServerError)
+	release(r.timeService.Since(fetchStart), failed)
+	return resp, err
+}
+
+func (r *LimitedOriginRepository) HealthCheck(ctx context.Context) error {
+	return r.origin.HealthCheck(ctx)
+}
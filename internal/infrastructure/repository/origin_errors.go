@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+// newOriginError wraps err with the kind of failure it represents; fallback is used when
+// the error carries no more specific information.
+func newOriginError(err error, fallback string) error {
+	return &entity.OriginError{Kind: classifyOriginError(err, fallback), Err: err}
+}
+
+func classifyOriginError(err error, fallback string) string {
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var tlsRecordErr tls.RecordHeaderError
+	var tlsVerifyErr *tls.CertificateVerificationError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()):
+		return entity.OriginErrorTimeout
+	case errors.As(err, &dnsErr):
+		return entity.OriginErrorDNS
+	case errors.As(err, &tlsRecordErr) || errors.As(err, &tlsVerifyErr):
+		return entity.OriginErrorTLS
+	case errors.As(err, &opErr):
+		return entity.OriginErrorConnection
+	default:
+		return fallback
+	}
+}
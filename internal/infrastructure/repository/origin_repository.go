@@ -3,9 +3,12 @@ package repository
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strings"
 	"time"
@@ -13,34 +16,93 @@ import (
 	"github.com/google/uuid"
 	"github.com/mikiasgoitom/RevProx/internal/contract"
 	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
 )
 
 type OriginRepository struct {
 	client      *http.Client
 	originUrl   *url.URL
+	timeouts    valueobject.OriginTimeouts
+	metrics     contract.IMetricsAdapter
 	timeService contract.ITimeService
+	logger      contract.ILogger
+	// h2c is set when cleartext HTTP/2 is spoken to this upstream.
+	h2c bool
 }
 
-func NewHttpOriginRepository(originUrl string, timeService contract.ITimeService) (contract.IOriginRepository, error) {
+func NewHttpOriginRepository(originUrl string, timeouts valueobject.OriginTimeouts, pool valueobject.OriginTransport, metrics contract.IMetricsAdapter, timeService contract.ITimeService, logger contract.ILogger) (contract.IOriginRepository, error) {
 	parsedUrl, err := url.Parse(originUrl)
 	if err != nil {
 		return nil, err
 	}
 
-	client := http.Client{
-		Timeout: 30 * time.Second,
-	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{Timeout: timeouts.Dial, KeepAlive: 30 * time.Second}
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = timeouts.TLSHandshake
+	transport.ResponseHeaderTimeout = timeouts.ResponseHeader
+	transport.MaxIdleConns = pool.MaxIdleConns
+	transport.MaxIdleConnsPerHost = pool.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = pool.MaxConnsPerHost
+	transport.IdleConnTimeout = pool.IdleConnTimeout
+	transport.DisableKeepAlives = pool.DisableKeepAlives
+	h2c := pool.H2C && parsedUrl.Scheme == "http"
+	transport.Protocols = originProtocols(pool, h2c)
+	// the total timeout is applied per request so routes can override it
+	client := http.Client{Transport: transport}
 
 	return &OriginRepository{
 		client:      &client,
 		originUrl:   parsedUrl,
+		timeouts:    timeouts,
+		metrics:     metrics,
 		timeService: timeService,
+		logger:      logger,
+		h2c:         h2c,
 	}, nil
 }
 
+// originProtocols selects the protocols offered to the upstream. The transport only uses
+// cleartext HTTP/2 when HTTP/1 is not offered, so h2c excludes HTTP/1 entirely; it is
+// therefore only enabled for http:// upstreams, leaving TLS ones to negotiate.
+func originProtocols(pool valueobject.OriginTransport, h2c bool) *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(!h2c)
+	protocols.SetHTTP2(pool.HTTP2 || h2c)
+	protocols.SetUnencryptedHTTP2(h2c)
+	return protocols
+}
+
+// connProtocol names the protocol spoken on conn the way http.Response.Proto does.
+func (r *OriginRepository) connProtocol(conn net.Conn) string {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if tlsConn.ConnectionState().NegotiatedProtocol == "h2" {
+			return "HTTP/2.0"
+		}
+		return "HTTP/1.1"
+	}
+	if r.h2c {
+		return "HTTP/2.0"
+	}
+	return "HTTP/1.1"
+}
+
 func (r *OriginRepository) Fetch(ctx context.Context, req entity.RequestModel) (entity.ResponseModel, error) {
+	if total := r.timeouts.TotalFor(req.URL.Path); total > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, total)
+		defer cancel()
+	}
 	targetUrl := r.originUrl.ResolveReference(req.URL)
-	originReq, err := http.NewRequestWithContext(ctx, req.Method, targetUrl.String(), bytes.NewReader(req.Body))
+	// connections are counted as soon as they are taken, so requests that fail afterwards
+	// still show up
+	tracer := newUpstreamTracer(r.timeService, func(info httptrace.GotConnInfo) {
+		if err := r.metrics.RecordOriginConnection(ctx, info.Reused, r.connProtocol(info.Conn)); err != nil {
+			r.logger.Error(ctx, "Metrics RecordOriginConnection error", valueobject.LogField{Key: "error", Value: err.Error()})
+		}
+	})
+	traceCtx := httptrace.WithClientTrace(ctx, tracer.clientTrace())
+	originReq, err := http.NewRequestWithContext(traceCtx, req.Method, targetUrl.String(), bytes.NewReader(req.Body))
 	if err != nil {
 		return entity.ResponseModel{}, fmt.Errorf("failed to create origin request: %w", err)
 	}
@@ -48,13 +110,15 @@ func (r *OriginRepository) Fetch(ctx context.Context, req entity.RequestModel) (
 
 	httpResp, err := r.client.Do(originReq)
 	if err != nil {
-		return entity.ResponseModel{}, fmt.Errorf("failed to perform origin request: %w", err)
+		return entity.ResponseModel{}, newOriginError(fmt.Errorf("failed to perform origin request: %w", err), entity.OriginErrorOther)
 	}
 	defer httpResp.Body.Close()
 	body, err := io.ReadAll(httpResp.Body)
 	if err != nil {
-		return entity.ResponseModel{}, fmt.Errorf("failed to read origin response body: %w", err)
+		return entity.ResponseModel{}, newOriginError(fmt.Errorf("failed to read origin response body: %w", err), entity.OriginErrorRead)
 	}
+	timing := tracer.finish()
+	timing.Bytes = len(body)
 	cacheControlHeader := httpResp.Header.Get("Cache-Control")
 	response := entity.ResponseModel{
 		ID:          uuid.New().String(),
@@ -63,12 +127,17 @@ func (r *OriginRepository) Fetch(ctx context.Context, req entity.RequestModel) (
 		Body:        body,
 		GeneratedAt: r.timeService.NowUnix(),
 		Cacheable:   strings.Contains(cacheControlHeader, "public"),
+		Timing:      timing,
 	}
 	return response, nil
 }
 func (r *OriginRepository) HealthCheck(ctx context.Context) error {
-	healthCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+	healthCtx := ctx
+	if r.timeouts.HealthCheck > 0 {
+		var cancel context.CancelFunc
+		healthCtx, cancel = context.WithTimeout(ctx, r.timeouts.HealthCheck)
+		defer cancel()
+	}
 
 	req, err := http.NewRequestWithContext(healthCtx, http.MethodHead, r.originUrl.String(), nil)
 	if err != nil {
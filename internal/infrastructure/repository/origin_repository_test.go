@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+	"github.com/mikiasgoitom/RevProx/internal/infrastructure/timeservice"
+)
+
+// connMetrics records origin connections; the other metrics are not used by Fetch.
+type connMetrics struct {
+	contract.IMetricsAdapter
+	mu    sync.Mutex
+	conns []string
+}
+
+func (m *connMetrics) RecordOriginConnection(ctx context.Context, reused bool, protocol string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := "new"
+	if reused {
+		state = "reused"
+	}
+	m.conns = append(m.conns, state+" "+protocol)
+	return nil
+}
+
+func (m *connMetrics) recorded() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.conns...)
+}
+
+func newTestOrigin(t *testing.T, originURL string, pool valueobject.OriginTransport, metrics contract.IMetricsAdapter) *OriginRepository {
+	t.Helper()
+	repo, err := NewHttpOriginRepository(originURL, valueobject.OriginTimeouts{Total: 5 * time.Second}, pool, metrics, timeservice.NewTimeService(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo.(*OriginRepository)
+}
+
+func TestOriginRepositoryOnlyUsesH2CForCleartextUpstreams(t *testing.T) {
+	tests := []struct {
+		name      string
+		originURL string
+		pool      valueobject.OriginTransport
+		wantH2C   bool
+		wantHTTP1 bool
+	}{
+		{name: "h2c for http", originURL: "http://origin.test", pool: valueobject.OriginTransport{H2C: true}, wantH2C: true},
+		{name: "https keeps negotiating", originURL: "https://origin.test", pool: valueobject.OriginTransport{H2C: true, HTTP2: true}, wantHTTP1: true},
+		{name: "h2c off", originURL: "http://origin.test", pool: valueobject.OriginTransport{HTTP2: true}, wantHTTP1: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestOrigin(t, tt.originURL, tt.pool, &connMetrics{})
+			protocols := repo.client.Transport.(*http.Transport).Protocols
+			if repo.h2c != tt.wantH2C || protocols.UnencryptedHTTP2() != tt.wantH2C {
+				t.Errorf("h2c = %v, unencrypted HTTP/2 = %v, want %v", repo.h2c, protocols.UnencryptedHTTP2(), tt.wantH2C)
+			}
+			if protocols.HTTP1() != tt.wantHTTP1 {
+				t.Errorf("HTTP/1 = %v, want %v", protocols.HTTP1(), tt.wantHTTP1)
+			}
+		})
+	}
+}
+
+func TestOriginRepositoryCountsConnectionsOfFailedRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer srv.Close()
+
+	metrics := &connMetrics{}
+	repo := newTestOrigin(t, srv.URL, valueobject.OriginTransport{}, metrics)
+	req := entity.RequestModel{Method: http.MethodPost, URL: &url.URL{Path: "/items"}, Headers: http.Header{}}
+	if _, err := repo.Fetch(context.Background(), req); err == nil {
+		t.Fatal("Fetch succeeded against a connection closed without a response")
+	}
+	if got := metrics.recorded(); len(got) == 0 || got[0] != "new HTTP/1.1" {
+		t.Fatalf("connections = %v, want the failed request's new HTTP/1.1 connection", got)
+	}
+}
+
+func TestOriginRepositoryCountsH2CConnections(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	metrics := &connMetrics{}
+	repo := newTestOrigin(t, srv.URL, valueobject.OriginTransport{H2C: true, MaxIdleConnsPerHost: 1}, metrics)
+	req := entity.RequestModel{Method: http.MethodGet, URL: &url.URL{Path: "/items"}, Headers: http.Header{}}
+	for i := 0; i < 2; i++ {
+		resp, err := repo.Fetch(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(resp.Body) != "HTTP/2.0" {
+			t.Fatalf("origin saw %s, want HTTP/2.0", resp.Body)
+		}
+	}
+	got := metrics.recorded()
+	want := []string{"new HTTP/2.0", "reused HTTP/2.0"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("connections = %v, want %v", got, want)
+	}
+}
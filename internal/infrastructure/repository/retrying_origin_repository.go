@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// Outcomes of retry and hedge decisions, used to label metrics.
+const (
+	retryOutcomeRetried        = "retried"
+	retryOutcomeBudgetExceeded = "budget_exhausted"
+	hedgeOutcomeWon            = "won"
+	hedgeOutcomeLost           = "lost"
+	hedgeOutcomeBudgetExceeded = "budget_exhausted"
+)
+
+// RetryingOriginRepository repeats failed fetches of idempotent requests with exponential
+// backoff and can hedge slow ones. Attempts rotate through the upstreams, starting with the
+// first, and a hedge goes to the upstream after the one being waited on. Every retry and
+// hedge is paid for from a shared budget.
+type RetryingOriginRepository struct {
+	upstreams   []contract.IOriginRepository
+	policy      valueobject.RetryPolicy
+	budget      contract.IRetryBudget
+	metrics     contract.IMetricsAdapter
+	timeService contract.ITimeService
+	logger      contract.ILogger
+}
+
+type fetchResult struct {
+	resp   entity.ResponseModel
+	err    error
+	hedged bool
+}
+
+func NewRetryingOriginRepository(upstreams []contract.IOriginRepository, policy valueobject.RetryPolicy, budget contract.IRetryBudget, metrics contract.IMetricsAdapter, timeService contract.ITimeService, logger contract.ILogger) contract.IOriginRepository {
+	policy.MaxAttempts = max(policy.MaxAttempts, 1)
+	return &RetryingOriginRepository{
+		upstreams:   upstreams,
+		policy:      policy,
+		budget:      budget,
+		metrics:     metrics,
+		timeService: timeService,
+		logger:      logger,
+	}
+}
+
+func (r *RetryingOriginRepository) Fetch(ctx context.Context, req entity.RequestModel) (entity.ResponseModel, error) {
+	r.budget.Deposit(r.timeService.Now())
+	if !r.policy.Retryable(req.Method) {
+		return r.upstreams[0].Fetch(ctx, req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := r.attempt(ctx, req, attempt)
+		reason := r.retryReason(resp, err)
+		if reason == "" || attempt >= r.policy.MaxAttempts || ctx.Err() != nil {
+			return resp, err
+		}
+		if !r.budget.Withdraw(r.timeService.Now()) {
+			r.recordRetry(ctx, reason, retryOutcomeBudgetExceeded)
+			r.logger.Warn(ctx, "Origin retry budget exhausted", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "path", Value: req.URL.Path}, valueobject.LogField{Key: "reason", Value: reason}, valueobject.LogField{Key: "attempt", Value: attempt})
+			return resp, err
+		}
+		delay := r.backoff(attempt)
+		r.recordRetry(ctx, reason, retryOutcomeRetried)
+		r.logger.Warn(ctx, "Retrying origin request", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "path", Value: req.URL.Path}, valueobject.LogField{Key: "reason", Value: reason}, valueobject.LogField{Key: "attempt", Value: attempt}, valueobject.LogField{Key: "delay_ms", Value: valueobject.Millis(delay)})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, err
+		}
+	}
+}
+
+// HealthCheck probes the primary upstream only.
+func (r *RetryingOriginRepository) HealthCheck(ctx context.Context) error {
+	return r.upstreams[0].HealthCheck(ctx)
+}
+
+// attempt performs one try, hedged when the policy asks for it and there is another
+// upstream to hedge to. The first usable answer wins and cancels the other request; when
+// neither is usable the later one is returned.
+func (r *RetryingOriginRepository) attempt(ctx context.Context, req entity.RequestModel, attempt int) (entity.ResponseModel, error) {
+	primary := r.upstreams[(attempt-1)%len(r.upstreams)]
+	if r.policy.HedgeAfter <= 0 || len(r.upstreams) == 1 {
+		return primary.Fetch(ctx, req)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan fetchResult, 2)
+	go func() {
+		resp, err := primary.Fetch(ctx, req)
+		results <- fetchResult{resp: resp, err: err}
+	}()
+
+	timer := time.NewTimer(r.policy.HedgeAfter)
+	defer timer.Stop()
+	select {
+	case res := <-results:
+		return res.resp, res.err
+	case <-timer.C:
+	}
+	if !r.budget.Withdraw(r.timeService.Now()) {
+		r.recordHedge(ctx, hedgeOutcomeBudgetExceeded)
+		res := <-results
+		return res.resp, res.err
+	}
+	r.logger.Info(ctx, "Hedging slow origin request", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "path", Value: req.URL.Path}, valueobject.LogField{Key: "after_ms", Value: valueobject.Millis(r.policy.HedgeAfter)})
+	hedge := r.upstreams[attempt%len(r.upstreams)]
+	go func() {
+		resp, err := hedge.Fetch(ctx, req)
+		results <- fetchResult{resp: resp, err: err, hedged: true}
+	}()
+
+	res := <-results
+	if r.retryReason(res.resp, res.err) != "" {
+		res = <-results
+	}
+	if res.hedged {
+		r.recordHedge(ctx, hedgeOutcomeWon)
+	} else {
+		r.recordHedge(ctx, hedgeOutcomeLost)
+	}
+	return res.resp, res.err
+}
+
+// retryReason names why the outcome of an attempt is worth retrying, or returns "" when it
+// is not. Requests shed by a concurrency limit are not retried so that retries cannot
+// deepen an overload.
+func (r *RetryingOriginRepository) retryReason(resp entity.ResponseModel, err error) string {
+	if err == nil {
+		if r.policy.RetriesStatus(resp.Status) {
+			return "status_" + strconv.Itoa(resp.Status)
+		}
+		return ""
+	}
+	var originErr *entity.OriginError
+	if !errors.As(err, &originErr) {
+		return ""
+	}
+	switch originErr.Kind {
+	case entity.OriginErrorTimeout, entity.OriginErrorConnection, entity.OriginErrorDNS, entity.OriginErrorRead:
+		return originErr.Kind
+	default:
+		return ""
+	}
+}
+
+// backoff returns the wait before retry number attempt using exponential backoff with full
+// jitter. A MaxDelay below BaseDelay caps the wait at BaseDelay rather than dropping it, so
+// retries are never fired back to back while a delay is configured.
+func (r *RetryingOriginRepository) backoff(attempt int) time.Duration {
+	limit := max(r.policy.MaxDelay, r.policy.BaseDelay)
+	ceiling := r.policy.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > limit {
+		ceiling = limit
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+func (r *RetryingOriginRepository) recordRetry(ctx context.Context, reason string, outcome string) {
+	if err := r.metrics.RecordOriginRetry(ctx, reason, outcome); err != nil {
+		r.logger.Error(ctx, "Metrics RecordOriginRetry error", valueobject.LogField{Key: "error", Value: err.Error()})
+	}
+}
+
+func (r *RetryingOriginRepository) recordHedge(ctx context.Context, outcome string) {
+	if err := r.metrics.RecordOriginHedge(ctx, outcome); err != nil {
+		r.logger.Error(ctx, "Metrics RecordOriginHedge error", valueobject.LogField{Key: "error", Value: err.Error()})
+	}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	domainservice "github.com/mikiasgoitom/RevProx/internal/domain/service"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+	"github.com/mikiasgoitom/RevProx/internal/infrastructure/timeservice"
+)
+
+func TestRetryingOriginRepositoryBackoffIsFullJitter(t *testing.T) {
+	policy := valueobject.RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	r := &RetryingOriginRepository{policy: policy}
+
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{attempt: 1, ceiling: 10 * time.Millisecond},
+		{attempt: 2, ceiling: 20 * time.Millisecond},
+		{attempt: 3, ceiling: 40 * time.Millisecond},
+		{attempt: 4, ceiling: 50 * time.Millisecond},
+		// a shift past the width of a duration must still be capped rather than wrap
+		{attempt: 70, ceiling: 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		var longest time.Duration
+		for i := 0; i < 1000; i++ {
+			d := r.backoff(tt.attempt)
+			if d < 0 || d >= tt.ceiling {
+				t.Fatalf("backoff(%d) = %s, want within [0, %s)", tt.attempt, d, tt.ceiling)
+			}
+			longest = max(longest, d)
+		}
+		// the draw is uniform, so 1000 of them all landing in the lower half means no jitter
+		if longest < tt.ceiling/2 {
+			t.Errorf("backoff(%d) never exceeded %s in 1000 draws, want spread up to %s", tt.attempt, longest, tt.ceiling)
+		}
+	}
+}
+
+func TestRetryingOriginRepositoryBackoffWithoutDelay(t *testing.T) {
+	r := &RetryingOriginRepository{}
+	if got := r.backoff(3); got != 0 {
+		t.Fatalf("backoff without delays = %s, want 0", got)
+	}
+}
+
+func TestRetryingOriginRepositoryBackoffWithoutMaxDelay(t *testing.T) {
+	r := &RetryingOriginRepository{policy: valueobject.RetryPolicy{BaseDelay: 10 * time.Millisecond}}
+	for _, attempt := range []int{1, 2, 5} {
+		var longest time.Duration
+		for i := 0; i < 1000; i++ {
+			d := r.backoff(attempt)
+			if d < 0 || d >= 10*time.Millisecond {
+				t.Fatalf("backoff(%d) = %s, want within [0, 10ms) capped at the base delay", attempt, d)
+			}
+			longest = max(longest, d)
+		}
+		if longest == 0 {
+			t.Fatalf("backoff(%d) was always 0 with a base delay of 10ms", attempt)
+		}
+	}
+}
+
+// slowOrigin answers every fetch after delay and counts them.
+type slowOrigin struct {
+	delay   time.Duration
+	fetches atomic.Int32
+}
+
+func (o *slowOrigin) Fetch(ctx context.Context, req entity.RequestModel) (entity.ResponseModel, error) {
+	o.fetches.Add(1)
+	select {
+	case <-time.After(o.delay):
+		return entity.ResponseModel{Status: http.StatusOK}, nil
+	case <-ctx.Done():
+		return entity.ResponseModel{}, ctx.Err()
+	}
+}
+
+func (o *slowOrigin) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+func TestRetryingOriginRepositoryDoesNotHedgeToItself(t *testing.T) {
+	origin := &slowOrigin{delay: 20 * time.Millisecond}
+	budget := domainservice.NewRetryBudget(0, 10)
+	policy := valueobject.RetryPolicy{MaxAttempts: 1, HedgeAfter: time.Millisecond}
+	r := NewRetryingOriginRepository([]contract.IOriginRepository{origin}, policy, budget, nil, timeservice.NewTimeService(), nil)
+
+	req := entity.RequestModel{Method: http.MethodGet, URL: &url.URL{Path: "/items"}}
+	resp, err := r.Fetch(context.Background(), req)
+	if err != nil || resp.Status != http.StatusOK {
+		t.Fatalf("Fetch = %d, %v, want 200", resp.Status, err)
+	}
+	if got := origin.fetches.Load(); got != 1 {
+		t.Fatalf("origin fetched %d times, want 1 with a single upstream", got)
+	}
+	// the hedge was never paid for
+	withdrawn := 0
+	for budget.Withdraw(time.Now()) && withdrawn < 100 {
+		withdrawn++
+	}
+	if withdrawn < 10 {
+		t.Fatalf("budget had %d retries left, want the initial 10 untouched", withdrawn)
+	}
+}
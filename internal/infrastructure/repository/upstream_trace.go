@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+// upstreamTracer collects per-phase timings of one origin request through httptrace.
+// Dial callbacks can fire from several goroutines, hence the lock.
+type upstreamTracer struct {
+	mu           sync.Mutex
+	timeService  contract.ITimeService
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	timing       entity.UpstreamTiming
+	// gotConn is called, outside the lock, with every connection the request is sent on.
+	gotConn func(httptrace.GotConnInfo)
+}
+
+func newUpstreamTracer(timeService contract.ITimeService, gotConn func(httptrace.GotConnInfo)) *upstreamTracer {
+	return &upstreamTracer{timeService: timeService, start: timeService.Monotonic(), gotConn: gotConn}
+}
+
+func (t *upstreamTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = t.timeService.Monotonic()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			t.timing.DNS = t.timeService.Since(t.dnsStart)
+			t.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			if t.connectStart.IsZero() {
+				t.connectStart = t.timeService.Monotonic()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone: func(_ string, _ string, err error) {
+			t.mu.Lock()
+			if err == nil && t.timing.Connect == 0 {
+				t.timing.Connect = t.timeService.Since(t.connectStart)
+			}
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsStart = t.timeService.Monotonic()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			t.timing.TLS = t.timeService.Since(t.tlsStart)
+			t.mu.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			if info.Conn != nil {
+				t.timing.Addr = info.Conn.RemoteAddr().String()
+			}
+			t.timing.ConnReused = info.Reused
+			t.mu.Unlock()
+			if t.gotConn != nil {
+				t.gotConn(info)
+			}
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			t.wroteRequest = t.timeService.Monotonic()
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			t.firstByte = t.timeService.Monotonic()
+			// measured from the end of the request so that it is the origin's think time,
+			// not the dial and handshakes already counted in their own phases
+			if !t.wroteRequest.IsZero() {
+				t.timing.TTFB = t.firstByte.Sub(t.wroteRequest)
+			}
+			t.mu.Unlock()
+		},
+	}
+}
+
+// finish marks the end of the body transfer and returns the collected timings.
+func (t *upstreamTracer) finish() entity.UpstreamTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.firstByte.IsZero() {
+		t.timing.Transfer = t.timeService.Since(t.firstByte)
+	}
+	t.timing.Total = t.timeService.Since(t.start)
+	return t.timing
+}
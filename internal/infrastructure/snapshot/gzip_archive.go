@@ -0,0 +1,135 @@
+package snapshot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+const (
+	archiveFormat = "revprox-cache-snapshot"
+	// archiveVersion is bumped whenever the entry layout changes incompatibly.
+	archiveVersion = 1
+)
+
+// archiveHeader is the first JSON document of an archive.
+type archiveHeader struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries   int       `json:"entries"`
+}
+
+// archiveEntry is one cached response. Bodies are base64 encoded by encoding/json.
+type archiveEntry struct {
+	Method        string      `json:"method"`
+	NormalizedURL string      `json:"normalized_url"`
+	Namespace     string      `json:"namespace,omitempty"`
+	Variant       string      `json:"variant,omitempty"`
+	Status        int         `json:"status"`
+	Headers       http.Header `json:"headers"`
+	Body          []byte      `json:"body"`
+	GeneratedAt   int64       `json:"generated_at"`
+	StoredAt      int64       `json:"stored_at"`
+	ExpiresAt     int64       `json:"expires_at"`
+	StaleUntil    int64       `json:"stale_until"`
+	Freshness     string      `json:"freshness,omitempty"`
+}
+
+// GzipArchive stores snapshots as gzip-compressed JSON lines: a header followed by one
+// line per entry, so archives can be inspected with zcat.
+type GzipArchive struct{}
+
+func NewGzipArchive() contract.ISnapshotArchive {
+	return &GzipArchive{}
+}
+
+func (a *GzipArchive) Write(w io.Writer, info entity.SnapshotInfo, entries []entity.CacheEntry) error {
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+	header := archiveHeader{Format: archiveFormat, Version: archiveVersion, CreatedAt: info.CreatedAt.UTC(), Entries: len(entries)}
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("failed to write snapshot header: %w", err)
+	}
+	for _, e := range entries {
+		line := archiveEntry{
+			Method:        e.Key.Method,
+			NormalizedURL: e.Key.NormalizedURL,
+			Namespace:     e.Key.Namespace,
+			Variant:       e.Key.Variant,
+			Status:        e.Payload.Status,
+			Headers:       e.Payload.Headers,
+			Body:          e.Payload.Body,
+			GeneratedAt:   e.Payload.GeneratedAt,
+			StoredAt:      e.StoredAt,
+			ExpiresAt:     e.ExpiresAt,
+			StaleUntil:    e.StaleUntil,
+			Freshness:     string(e.Freshness),
+		}
+		if err := enc.Encode(line); err != nil {
+			return fmt.Errorf("failed to write snapshot entry: %w", err)
+		}
+	}
+	return zw.Close()
+}
+
+func (a *GzipArchive) Read(r io.Reader, visit func(entity.CacheEntry) error) (entity.SnapshotInfo, error) {
+	zr, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return entity.SnapshotInfo{}, fmt.Errorf("snapshot is not gzip compressed: %w", err)
+	}
+	defer zr.Close()
+	dec := json.NewDecoder(zr)
+
+	var header archiveHeader
+	if err := dec.Decode(&header); err != nil {
+		return entity.SnapshotInfo{}, fmt.Errorf("failed to read snapshot header: %w", err)
+	}
+	if header.Format != archiveFormat {
+		return entity.SnapshotInfo{}, fmt.Errorf("not a cache snapshot (format %q)", header.Format)
+	}
+	if header.Version != archiveVersion {
+		return entity.SnapshotInfo{}, fmt.Errorf("unsupported snapshot version %d, want %d", header.Version, archiveVersion)
+	}
+	info := entity.SnapshotInfo{Version: header.Version, CreatedAt: header.CreatedAt, Entries: header.Entries}
+
+	for {
+		var line archiveEntry
+		if err := dec.Decode(&line); errors.Is(err, io.EOF) {
+			return info, nil
+		} else if err != nil {
+			return info, fmt.Errorf("failed to read snapshot entry: %w", err)
+		}
+		entry := entity.CacheEntry{
+			Key: valueobject.CacheKey{
+				Method:        line.Method,
+				NormalizedURL: line.NormalizedURL,
+				Namespace:     line.Namespace,
+				Variant:       line.Variant,
+			},
+			Payload: entity.ResponseModel{
+				Status:      line.Status,
+				Headers:     line.Headers,
+				Body:        line.Body,
+				GeneratedAt: line.GeneratedAt,
+				Cacheable:   true,
+			},
+			StoredAt:   line.StoredAt,
+			ExpiresAt:  line.ExpiresAt,
+			StaleUntil: line.StaleUntil,
+			Freshness:  valueobject.FreshnessSource(line.Freshness),
+		}
+		if err := visit(entry); err != nil {
+			return info, err
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+func TestGzipArchiveRoundTrip(t *testing.T) {
+	created := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	entries := []entity.CacheEntry{
+		{
+			Key:        valueobject.CacheKey{Method: "GET", NormalizedURL: "/items", Namespace: "shop", Variant: "accept-language=de"},
+			Payload:    entity.ResponseModel{Status: http.StatusOK, Headers: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{"items":[]}`), GeneratedAt: 990, Cacheable: true},
+			StoredAt:   1000,
+			ExpiresAt:  1060,
+			StaleUntil: 1120,
+			Freshness:  valueobject.FreshnessExplicit,
+		},
+		{
+			Key:       valueobject.CacheKey{Method: "GET", NormalizedURL: "/logo.png"},
+			Payload:   entity.ResponseModel{Status: http.StatusNotFound, Headers: http.Header{}, Body: []byte{0x89, 'P', 'N', 'G', 0x00}, Cacheable: true},
+			StoredAt:  1000,
+			ExpiresAt: 1030,
+		},
+	}
+
+	var buf bytes.Buffer
+	archive := NewGzipArchive()
+	if err := archive.Write(&buf, entity.SnapshotInfo{CreatedAt: created}, entries); err != nil {
+		t.Fatal(err)
+	}
+	var got []entity.CacheEntry
+	info, err := archive.Read(&buf, func(e entity.CacheEntry) error {
+		got = append(got, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := entity.SnapshotInfo{Version: archiveVersion, CreatedAt: created, Entries: len(entries)}
+	if info != want {
+		t.Errorf("info = %+v, want %+v", info, want)
+	}
+	if !reflect.DeepEqual(got, entries) {
+		t.Fatalf("entries = %+v\nwant      %+v", got, entries)
+	}
+}
+
+func TestGzipArchiveRejectsOtherVersions(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	json.NewEncoder(zw).Encode(archiveHeader{Format: archiveFormat, Version: archiveVersion + 1, Entries: 1})
+	json.NewEncoder(zw).Encode(archiveEntry{Method: "GET", NormalizedURL: "/items", Status: http.StatusOK})
+	zw.Close()
+
+	visited := 0
+	_, err := NewGzipArchive().Read(&buf, func(entity.CacheEntry) error {
+		visited++
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported snapshot version") {
+		t.Fatalf("Read() error = %v, want an unsupported version error", err)
+	}
+	if visited != 0 {
+		t.Fatalf("visited %d entries of an unsupported archive, want 0", visited)
+	}
+}
+
+func TestGzipArchiveRejectsOtherFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content func() []byte
+		wantErr string
+	}{
+		{name: "not gzip", content: func() []byte { return []byte("plain text") }, wantErr: "not gzip compressed"},
+		{
+			name: "other format",
+			content: func() []byte {
+				var buf bytes.Buffer
+				zw := gzip.NewWriter(&buf)
+				json.NewEncoder(zw).Encode(archiveHeader{Format: "something-else", Version: archiveVersion})
+				zw.Close()
+				return buf.Bytes()
+			},
+			wantErr: "not a cache snapshot",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGzipArchive().Read(bytes.NewReader(tt.content()), func(entity.CacheEntry) error { return nil })
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Read() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -22,3 +22,12 @@ func (ts *TimeService) Now() time.Time {
 func (ts *TimeService) NowUnix() int64 {
 	return time.Now().UTC().Unix()
 }
+
+// Monotonic keeps the monotonic clock reading that Now strips by converting to UTC.
+func (ts *TimeService) Monotonic() time.Time {
+	return time.Now()
+}
+
+func (ts *TimeService) Since(start time.Time) time.Duration {
+	return time.Since(start)
+}
@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/mikiasgoitom/RevProx"
+
+// OtelTracer implements ITracer on top of OpenTelemetry.
+type OtelTracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewOtelTracer sets up the OpenTelemetry SDK from cfg. The returned shutdown function
+// flushes pending spans. When tracing is disabled spans are not recorded, but incoming
+// trace context is still propagated to the origin.
+func NewOtelTracer(ctx context.Context, cfg config.TracingConfig) (contract.ITracer, func(context.Context) error, error) {
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	otel.SetTextMapPropagator(propagator)
+
+	if !cfg.Enabled {
+		return &OtelTracer{tracer: otel.Tracer(instrumentationName), propagator: propagator}, func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return &OtelTracer{tracer: provider.Tracer(instrumentationName), propagator: propagator}, provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "otlp", "":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+}
+
+func (t *OtelTracer) Start(ctx context.Context, name string, kind valueobject.SpanKind, attrs ...valueobject.LogField) (context.Context, contract.ISpan) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(toSpanKind(kind)), trace.WithAttributes(toAttributes(attrs)...))
+	return ctx, &otelSpan{span: span}
+}
+
+func (t *OtelTracer) Extract(ctx context.Context, headers http.Header) context.Context {
+	return t.propagator.Extract(ctx, propagation.HeaderCarrier(headers))
+}
+
+func (t *OtelTracer) Inject(ctx context.Context, headers http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(headers))
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s *otelSpan) SetAttributes(attrs ...valueobject.LogField) {
+	s.span.SetAttributes(toAttributes(attrs)...)
+}
+
+func (s *otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *otelSpan) End() {
+	s.span.End()
+}
+
+func toSpanKind(kind valueobject.SpanKind) trace.SpanKind {
+	switch kind {
+	case valueobject.SpanKindServer:
+		return trace.SpanKindServer
+	case valueobject.SpanKindClient:
+		return trace.SpanKindClient
+	default:
+		return trace.SpanKindInternal
+	}
+}
+
+func toAttributes(fields []valueobject.LogField) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(fields))
+	for _, field := range fields {
+		switch v := field.Value.(type) {
+		case string:
+			attrs = append(attrs, attribute.String(field.Key, v))
+		case bool:
+			attrs = append(attrs, attribute.Bool(field.Key, v))
+		case int:
+			attrs = append(attrs, attribute.Int(field.Key, v))
+		case int64:
+			attrs = append(attrs, attribute.Int64(field.Key, v))
+		case float64:
+			attrs = append(attrs, attribute.Float64(field.Key, v))
+		default:
+			attrs = append(attrs, attribute.String(field.Key, fmt.Sprint(v)))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,167 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mikiasgoitom/RevProx/internal/config"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpReceiver collects the spans posted to an OTLP/HTTP traces endpoint.
+type otlpReceiver struct {
+	mu       sync.Mutex
+	services []string
+	spans    []*tracepb.Span
+}
+
+func (rcv *otlpReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req coltracepb.ExportTraceServiceRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rcv.mu.Lock()
+	for _, rs := range req.ResourceSpans {
+		for _, attr := range rs.GetResource().GetAttributes() {
+			if attr.Key == "service.name" {
+				rcv.services = append(rcv.services, attr.GetValue().GetStringValue())
+			}
+		}
+		for _, ss := range rs.ScopeSpans {
+			rcv.spans = append(rcv.spans, ss.Spans...)
+		}
+	}
+	rcv.mu.Unlock()
+	resp, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(resp)
+}
+
+func (rcv *otlpReceiver) span(t *testing.T, name string) *tracepb.Span {
+	t.Helper()
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	for _, span := range rcv.spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	t.Fatalf("span %q was not exported", name)
+	return nil
+}
+
+func attributeValue(span *tracepb.Span, key string) *commonpb.AnyValue {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return nil
+}
+
+func TestOtelTracerExportsOverOTLP(t *testing.T) {
+	receiver := &otlpReceiver{}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	ctx := context.Background()
+	tracer, shutdown, err := NewOtelTracer(ctx, config.TracingConfig{
+		Enabled:     true,
+		Exporter:    "otlp",
+		Endpoint:    strings.TrimPrefix(srv.URL, "http://"),
+		Insecure:    true,
+		ServiceName: "revprox-test",
+		SampleRatio: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewOtelTracer: %v", err)
+	}
+
+	// the server span continues the trace of an incoming request
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const parentID = "00f067aa0ba902b7"
+	incoming := http.Header{"Traceparent": {"00-" + traceID + "-" + parentID + "-01"}}
+	serverCtx, serverSpan := tracer.Start(tracer.Extract(ctx, incoming), "proxy.request", valueobject.SpanKindServer, valueobject.LogField{Key: "http.method", Value: "GET"})
+	_, clientSpan := tracer.Start(serverCtx, "origin.fetch", valueobject.SpanKindClient)
+	clientSpan.SetAttributes(valueobject.LogField{Key: "http.status_code", Value: 502}, valueobject.LogField{Key: "retry", Value: true})
+	clientSpan.RecordError(errors.New("connection refused"))
+	clientSpan.End()
+	outgoing := http.Header{}
+	tracer.Inject(serverCtx, outgoing)
+	serverSpan.End()
+
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	server := receiver.span(t, "proxy.request")
+	if got := hex.EncodeToString(server.TraceId); got != traceID {
+		t.Errorf("server span trace id = %s, want %s", got, traceID)
+	}
+	if got := hex.EncodeToString(server.ParentSpanId); got != parentID {
+		t.Errorf("server span parent id = %s, want %s", got, parentID)
+	}
+	if server.Kind != tracepb.Span_SPAN_KIND_SERVER {
+		t.Errorf("server span kind = %v, want server", server.Kind)
+	}
+	if got := attributeValue(server, "http.method").GetStringValue(); got != "GET" {
+		t.Errorf("http.method = %q, want GET", got)
+	}
+	if want := "00-" + traceID + "-" + hex.EncodeToString(server.SpanId) + "-01"; outgoing.Get("Traceparent") != want {
+		t.Errorf("injected traceparent = %q, want %q", outgoing.Get("Traceparent"), want)
+	}
+
+	client := receiver.span(t, "origin.fetch")
+	if string(client.ParentSpanId) != string(server.SpanId) {
+		t.Errorf("client span parent = %x, want the server span %x", client.ParentSpanId, server.SpanId)
+	}
+	if client.Kind != tracepb.Span_SPAN_KIND_CLIENT {
+		t.Errorf("client span kind = %v, want client", client.Kind)
+	}
+	if got := attributeValue(client, "http.status_code").GetIntValue(); got != 502 {
+		t.Errorf("http.status_code = %d, want 502", got)
+	}
+	if got := attributeValue(client, "retry").GetBoolValue(); !got {
+		t.Errorf("retry = %v, want true", got)
+	}
+	if client.Status.GetCode() != tracepb.Status_STATUS_CODE_ERROR || client.Status.GetMessage() != "connection refused" {
+		t.Errorf("client span status = %v, want error", client.Status)
+	}
+	if len(client.Events) != 1 || client.Events[0].Name != "exception" {
+		t.Errorf("client span events = %v, want one exception", client.Events)
+	}
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	if len(receiver.services) == 0 || receiver.services[0] != "revprox-test" {
+		t.Errorf("service names = %v, want revprox-test", receiver.services)
+	}
+}
+
+func TestOtelTracerRejectsUnknownExporter(t *testing.T) {
+	_, _, err := NewOtelTracer(context.Background(), config.TracingConfig{Enabled: true, Exporter: "zipkin"})
+	if err == nil {
+		t.Fatal("NewOtelTracer accepted an unknown exporter")
+	}
+}
@@ -0,0 +1,124 @@
+package warmup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+var (
+	// clfRequest matches the request line and status of common and combined log lines.
+	clfRequest = regexp.MustCompile(`"(\S+) (\S+) [^"]*" (\d{3}) `)
+	logfmtPair = regexp.MustCompile(`(\w+)=("(?:[^"\\]|\\.)*"|\S*)`)
+)
+
+// AccessLogSource reads seed URLs from an access log written by this proxy in the common,
+// combined, json or logfmt format, gzip-compressed rotations included. Successful GET
+// requests are ranked by how often they were made, most requested first.
+type AccessLogSource struct {
+	path string
+}
+
+func NewAccessLogSource(path string) contract.IWarmupSource {
+	return &AccessLogSource{path: path}
+}
+
+func (s *AccessLogSource) Name() string {
+	return "access_log:" + s.path
+}
+
+func (s *AccessLogSource) URLs(ctx context.Context) ([]string, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open access log: %w", err)
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if strings.HasSuffix(s.path, ".gz") {
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read compressed access log: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		method, uri, status, ok := parseAccessLogLine(scanner.Text())
+		if !ok || method != http.MethodGet || status < 200 || status >= 300 {
+			continue
+		}
+		// access logs record the full request URI, including the proxy mount point
+		path, found := strings.CutPrefix(uri, valueobject.ProxyRoutePrefix)
+		if !found {
+			continue
+		}
+		if seed := seedPath(path); seed != "" {
+			counts[seed]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read access log: %w", err)
+	}
+
+	urls := make([]string, 0, len(counts))
+	for seed := range counts {
+		urls = append(urls, seed)
+	}
+	sort.Slice(urls, func(i, j int) bool {
+		if counts[urls[i]] != counts[urls[j]] {
+			return counts[urls[i]] > counts[urls[j]]
+		}
+		return urls[i] < urls[j]
+	})
+	return urls, nil
+}
+
+func parseAccessLogLine(line string) (method, uri string, status int, ok bool) {
+	line = strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(line, "{"):
+		var entry struct {
+			Method string `json:"method"`
+			URI    string `json:"uri"`
+			Status int    `json:"status"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return "", "", 0, false
+		}
+		return entry.Method, entry.URI, entry.Status, true
+	case strings.HasPrefix(line, "time="):
+		fields := make(map[string]string)
+		for _, m := range logfmtPair.FindAllStringSubmatch(line, -1) {
+			value := m[2]
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			fields[m[1]] = value
+		}
+		status, err := strconv.Atoi(fields["status"])
+		return fields["method"], fields["uri"], status, err == nil
+	default:
+		m := clfRequest.FindStringSubmatch(line)
+		if m == nil {
+			return "", "", 0, false
+		}
+		status, err := strconv.Atoi(m[3])
+		return m[1], m[2], status, err == nil
+	}
+}
@@ -0,0 +1,49 @@
+package warmup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+)
+
+// FileSource reads seed URLs from a text file, one absolute URL or path per line. Blank
+// lines and lines starting with # are skipped.
+type FileSource struct {
+	path string
+}
+
+func NewFileSource(path string) contract.IWarmupSource {
+	return &FileSource{path: path}
+}
+
+func (s *FileSource) Name() string {
+	return "file:" + s.path
+}
+
+func (s *FileSource) URLs(ctx context.Context) ([]string, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open warm-up URL file: %w", err)
+	}
+	defer file.Close()
+
+	var urls []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if seed := seedPath(line); seed != "" {
+			urls = append(urls, seed)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return urls, fmt.Errorf("failed to read warm-up URL file: %w", err)
+	}
+	return urls, nil
+}
@@ -0,0 +1,20 @@
+package warmup
+
+import (
+	"net/url"
+	"strings"
+)
+
+// seedPath reduces a seed, either an absolute URL or a path, to the origin path and query
+// the proxy fetches. It returns "" for seeds that are not usable.
+func seedPath(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Path == "" || !strings.HasPrefix(u.Path, "/") {
+		return ""
+	}
+	seed := u.EscapedPath()
+	if u.RawQuery != "" {
+		seed += "?" + u.RawQuery
+	}
+	return seed
+}
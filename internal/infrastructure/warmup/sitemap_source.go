@@ -0,0 +1,85 @@
+package warmup
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+// maxSitemapDepth bounds how many levels of sitemap indexes are followed.
+const maxSitemapDepth = 3
+
+// SitemapSource reads seed URLs from a sitemap.xml served by the origin. Sitemap indexes
+// are followed; only the path and query of each <loc> are used.
+type SitemapSource struct {
+	originRepo contract.IOriginRepository
+	path       string
+}
+
+func NewSitemapSource(originRepo contract.IOriginRepository, path string) contract.IWarmupSource {
+	return &SitemapSource{originRepo: originRepo, path: path}
+}
+
+func (s *SitemapSource) Name() string {
+	return "sitemap:" + s.path
+}
+
+// sitemapDocument matches both <urlset> and <sitemapindex> documents.
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+func (s *SitemapSource) URLs(ctx context.Context) ([]string, error) {
+	var urls []string
+	err := s.read(ctx, s.path, 0, &urls)
+	return urls, err
+}
+
+func (s *SitemapSource) read(ctx context.Context, path string, depth int, urls *[]string) error {
+	target, err := url.Parse(path)
+	if err != nil {
+		return fmt.Errorf("invalid sitemap path %q: %w", path, err)
+	}
+	resp, err := s.originRepo.Fetch(ctx, entity.RequestModel{
+		Method:  http.MethodGet,
+		URL:     &url.URL{Path: target.Path, RawQuery: target.RawQuery},
+		Headers: http.Header{"User-Agent": []string{"revprox-warmup/1"}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch sitemap %s: %w", path, err)
+	}
+	if resp.Status != http.StatusOK {
+		return fmt.Errorf("failed to fetch sitemap %s: origin answered %d", path, resp.Status)
+	}
+	var doc sitemapDocument
+	if err := xml.Unmarshal(resp.Body, &doc); err != nil {
+		return fmt.Errorf("failed to parse sitemap %s: %w", path, err)
+	}
+	for _, u := range doc.URLs {
+		if seed := seedPath(u.Loc); seed != "" {
+			*urls = append(*urls, seed)
+		}
+	}
+	if depth+1 >= maxSitemapDepth {
+		return nil
+	}
+	for _, child := range doc.Sitemaps {
+		if seed := seedPath(child.Loc); seed != "" {
+			if err := s.read(ctx, seed, depth+1, urls); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// canBackoff reports whether a request may start or be answered by a backoff. Only cache
+// lookups qualify: other methods and bypassed requests always reach the origin.
+func canBackoff(req entity.RequestModel, bypass bool) bool {
+	return !bypass && (req.Method == http.MethodGet || req.Method == http.MethodHead)
+}
+
+// retryAfter reads the Retry-After header of a 503 response, or of a 429 unless the policy
+// says the origin rate limits per client, bounded by the policy's MaxRetryAfter. A per
+// client 429 must not make every other client back off.
+func (uc *ProxyUseCase) retryAfter(resp entity.ResponseModel) (time.Duration, bool) {
+	switch {
+	case resp.Status == http.StatusServiceUnavailable:
+	case resp.Status == http.StatusTooManyRequests && uc.CachePolicy.BackoffOn429:
+	default:
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Headers.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	var d time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		d = at.Sub(uc.TimeService.Now())
+	}
+	if d <= 0 {
+		return 0, false
+	}
+	if uc.CachePolicy.MaxRetryAfter > 0 && d > uc.CachePolicy.MaxRetryAfter {
+		d = uc.CachePolicy.MaxRetryAfter
+	}
+	return d, true
+}
+
+// startBackoff remembers the origin's error response for the key until the Retry-After
+// deadline so that following requests are not forwarded.
+func (uc *ProxyUseCase) startBackoff(ctx context.Context, key valueobject.CacheKey, resp entity.ResponseModel, d time.Duration) {
+	now := uc.TimeService.NowUnix()
+	meta, _, err := uc.CacheRepository.GetMetadata(ctx, key)
+	if err != nil {
+		uc.Logger.Error(ctx, "Cache GetMetadata error", valueobject.LogField{Key: "error", Value: err.Error()}, valueobject.LogField{Key: "url", Value: key.NormalizedURL})
+	}
+	errResp := resp
+	errResp.Headers = resp.Headers.Clone()
+	meta.Key = key
+	meta.BackoffUntil = now + int64(d.Seconds())
+	meta.BackoffResponse = &errResp
+	meta.ExpiresAt = max(meta.ExpiresAt, meta.BackoffUntil)
+	if err := uc.CacheRepository.SetMetadata(ctx, meta); err != nil {
+		uc.Logger.Error(ctx, "Cache SetMetadata error", valueobject.LogField{Key: "error", Value: err.Error()}, valueobject.LogField{Key: "url", Value: key.NormalizedURL})
+		return
+	}
+	uc.Logger.Warn(ctx, "Origin requested backoff", valueobject.LogField{Key: "url", Value: key.NormalizedURL}, valueobject.LogField{Key: "status", Value: resp.Status}, valueobject.LogField{Key: "retry_after", Value: d.String()})
+}
+
+// serveDuringBackoff answers from the stale entry, or failing that the remembered error
+// response, while the key is backing off.
+func (uc *ProxyUseCase) serveDuringBackoff(ctx context.Context, key valueobject.CacheKey, entry entity.CacheEntry, stale bool) (entity.ResponseModel, bool) {
+	meta, found, err := uc.CacheRepository.GetMetadata(ctx, key)
+	if err != nil {
+		uc.Logger.Error(ctx, "Cache GetMetadata error", valueobject.LogField{Key: "error", Value: err.Error()}, valueobject.LogField{Key: "url", Value: key.NormalizedURL})
+		return entity.ResponseModel{}, false
+	}
+	now := uc.TimeService.NowUnix()
+	if !found || meta.BackoffUntil <= now {
+		return entity.ResponseModel{}, false
+	}
+	if stale {
+		return uc.serveStale(ctx, entry, "backoff"), true
+	}
+	if meta.BackoffResponse == nil {
+		return entity.ResponseModel{}, false
+	}
+	resp := *meta.BackoffResponse
+	resp.Headers = resp.Headers.Clone()
+	resp.Headers.Set("Retry-After", strconv.FormatInt(meta.BackoffUntil-now, 10))
+	resp.Headers.Set(cacheStatusHeader, cacheStatusBackoff())
+	resp.CacheOutcome = valueobject.CacheOutcomeHit
+	uc.Logger.Info(ctx, "Response served from backoff", valueobject.LogField{Key: "url", Value: key.NormalizedURL}, valueobject.LogField{Key: "status", Value: resp.Status})
+	return resp, true
+}
+
+// serveStale returns a stale entry in place of contacting, or after failing to get a usable
+// answer from, the origin.
+func (uc *ProxyUseCase) serveStale(ctx context.Context, entry entity.CacheEntry, detail string) entity.ResponseModel {
+	resp := entry.Payload
+	resp.Headers = resp.Headers.Clone()
+	resp.Headers.Set(cacheStatusHeader, cacheStatusStale(entry, uc.TimeService.NowUnix(), detail))
+	resp.CacheOutcome = valueobject.CacheOutcomeStale
+	uc.Logger.Info(ctx, "Stale response served", valueobject.LogField{Key: "url", Value: entry.Key.NormalizedURL}, valueobject.LogField{Key: "reason", Value: detail})
+	return resp
+}
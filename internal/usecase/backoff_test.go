@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// fixedTime is a clock stopped at now.
+type fixedTime struct{ now time.Time }
+
+func (f fixedTime) Now() time.Time                      { return f.now }
+func (f fixedTime) NowUnix() int64                      { return f.now.Unix() }
+func (f fixedTime) Monotonic() time.Time                { return f.now }
+func (f fixedTime) Since(start time.Time) time.Duration { return f.now.Sub(start) }
+
+// discardLogger drops every log line.
+type discardLogger struct{}
+
+func (discardLogger) Info(context.Context, string, ...valueobject.LogField)  {}
+func (discardLogger) Debug(context.Context, string, ...valueobject.LogField) {}
+func (discardLogger) Warn(context.Context, string, ...valueobject.LogField)  {}
+func (discardLogger) Error(context.Context, string, ...valueobject.LogField) {}
+func (discardLogger) Fatal(context.Context, string, ...valueobject.LogField) {}
+
+// metadataRepository serves one key's metadata; the other methods are not used by backoff.
+type metadataRepository struct {
+	contract.ICacheRepository
+	meta  entity.CacheMetadata
+	found bool
+}
+
+func (r *metadataRepository) GetMetadata(ctx context.Context, key valueobject.CacheKey) (entity.CacheMetadata, bool, error) {
+	return r.meta, r.found, nil
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		status int
+		value  string
+		policy entity.CachePolicy
+		want   time.Duration
+		ok     bool
+	}{
+		{name: "503 in seconds", status: http.StatusServiceUnavailable, value: "30", want: 30 * time.Second, ok: true},
+		{name: "503 as a date", status: http.StatusServiceUnavailable, value: now.Add(time.Minute).Format(http.TimeFormat), want: time.Minute, ok: true},
+		{name: "503 without Retry-After", status: http.StatusServiceUnavailable},
+		{name: "503 with a date in the past", status: http.StatusServiceUnavailable, value: now.Add(-time.Minute).Format(http.TimeFormat)},
+		{name: "503 with garbage", status: http.StatusServiceUnavailable, value: "soon"},
+		{name: "429 with backoff_on_429", status: http.StatusTooManyRequests, value: "30", policy: entity.CachePolicy{BackoffOn429: true}, want: 30 * time.Second, ok: true},
+		{name: "429 without backoff_on_429", status: http.StatusTooManyRequests, value: "30"},
+		{name: "other statuses", status: http.StatusBadGateway, value: "30", policy: entity.CachePolicy{BackoffOn429: true}},
+		{name: "capped by MaxRetryAfter", status: http.StatusServiceUnavailable, value: "3600", policy: entity.CachePolicy{MaxRetryAfter: time.Minute}, want: time.Minute, ok: true},
+		{name: "below MaxRetryAfter", status: http.StatusServiceUnavailable, value: "10", policy: entity.CachePolicy{MaxRetryAfter: time.Minute}, want: 10 * time.Second, ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &ProxyUseCase{TimeService: fixedTime{now}, CachePolicy: tt.policy}
+			headers := http.Header{}
+			if tt.value != "" {
+				headers.Set("Retry-After", tt.value)
+			}
+			got, ok := uc.retryAfter(entity.ResponseModel{Status: tt.status, Headers: headers})
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("retryAfter() = %s, %v, want %s, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestServeDuringBackoff(t *testing.T) {
+	now := time.Unix(1000, 0)
+	key := valueobject.CacheKey{NormalizedURL: "/items"}
+	errResp := &entity.ResponseModel{Status: http.StatusServiceUnavailable, Headers: http.Header{"Retry-After": {"30"}}, Body: []byte("busy")}
+	staleEntry := entity.CacheEntry{
+		Key:       key,
+		Payload:   entity.ResponseModel{Status: http.StatusOK, Headers: http.Header{}, Body: []byte("old")},
+		ExpiresAt: 990,
+	}
+
+	tests := []struct {
+		name       string
+		meta       entity.CacheMetadata
+		found      bool
+		stale      bool
+		served     bool
+		wantStatus int
+		wantBody   string
+		wantCache  string
+		wantRetry  string
+	}{
+		{name: "no metadata"},
+		{name: "backoff over", meta: entity.CacheMetadata{BackoffUntil: 1000, BackoffResponse: errResp}, found: true},
+		{
+			name:       "stale entry wins over the remembered error",
+			meta:       entity.CacheMetadata{BackoffUntil: 1020, BackoffResponse: errResp},
+			found:      true,
+			stale:      true,
+			served:     true,
+			wantStatus: http.StatusOK,
+			wantBody:   "old",
+			wantCache:  "RevProx; hit; ttl=-10; detail=backoff",
+		},
+		{
+			name:       "remembered error",
+			meta:       entity.CacheMetadata{BackoffUntil: 1020, BackoffResponse: errResp},
+			found:      true,
+			served:     true,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "busy",
+			wantCache:  "RevProx; hit; detail=backoff",
+			wantRetry:  "20",
+		},
+		{name: "backing off without a remembered error", meta: entity.CacheMetadata{BackoffUntil: 1020}, found: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &ProxyUseCase{
+				TimeService:     fixedTime{now},
+				CacheRepository: &metadataRepository{meta: tt.meta, found: tt.found},
+				Logger:          discardLogger{},
+			}
+			resp, served := uc.serveDuringBackoff(context.Background(), key, staleEntry, tt.stale)
+			if served != tt.served {
+				t.Fatalf("served = %v, want %v", served, tt.served)
+			}
+			if !served {
+				return
+			}
+			if resp.Status != tt.wantStatus || string(resp.Body) != tt.wantBody {
+				t.Errorf("response = %d %q, want %d %q", resp.Status, resp.Body, tt.wantStatus, tt.wantBody)
+			}
+			if got := resp.Headers.Get(cacheStatusHeader); got != tt.wantCache {
+				t.Errorf("Cache-Status = %q, want %q", got, tt.wantCache)
+			}
+			if got := resp.Headers.Get("Retry-After"); tt.wantRetry != "" && got != tt.wantRetry {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetry)
+			}
+		})
+	}
+	if got := errResp.Headers.Get("Retry-After"); got != "30" {
+		t.Fatalf("remembered response was modified: Retry-After = %q", got)
+	}
+}
@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+)
+
+type CacheInspectUseCase struct {
+	CacheRepo contract.ICacheRepository
+}
+
+func NewCacheInspectUseCase(cacheRepo contract.ICacheRepository) contract.ICacheInspectUseCase {
+	return &CacheInspectUseCase{CacheRepo: cacheRepo}
+}
+
+func (uc *CacheInspectUseCase) List(ctx context.Context, query entity.CacheEntryQuery) entity.CacheEntryPage {
+	return uc.CacheRepo.List(ctx, query)
+}
+
+func (uc *CacheInspectUseCase) Lookup(ctx context.Context, key string, includeBody bool) (entity.CacheEntryInfo, bool) {
+	return uc.CacheRepo.Lookup(ctx, key, includeBody)
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+const (
+	// cacheStatusHeader is the RFC 9211 response header describing how the proxy handled a request.
+	cacheStatusHeader = "Cache-Status"
+	cacheName         = "RevProx"
+)
+
+func cacheStatusHit(entry entity.CacheEntry, now int64) string {
+	params := []string{cacheName, "hit", "ttl=" + strconv.FormatInt(entry.ExpiresAt-now, 10)}
+	params = appendFreshnessDetail(params, entry.Freshness)
+	return strings.Join(params, "; ")
+}
+
+// cacheStatusStale describes a stale entry served without a successful origin round trip;
+// the negative ttl tells how long ago it expired.
+func cacheStatusStale(entry entity.CacheEntry, now int64, detail string) string {
+	return strings.Join([]string{cacheName, "hit", "ttl=" + strconv.FormatInt(entry.ExpiresAt-now, 10), "detail=" + detail}, "; ")
+}
+
+// cacheStatusBackoff describes a remembered origin error answered while its key backs off.
+func cacheStatusBackoff() string {
+	return strings.Join([]string{cacheName, "hit", "detail=backoff"}, "; ")
+}
+
+// cacheStatusForward describes a response that went to the origin; fwd is the RFC 9211
+// reason ("miss" or "stale") and fwdStatus the status the origin answered with.
+func cacheStatusForward(fwd string, fwdStatus int, stored bool, ttl int64, freshness valueobject.FreshnessSource) string {
+	params := []string{cacheName, "fwd=" + fwd}
+	if fwdStatus != 0 {
+		params = append(params, "fwd-status="+strconv.Itoa(fwdStatus))
+	}
+	if stored {
+		params = append(params, "stored", "ttl="+strconv.FormatInt(ttl, 10))
+		params = appendFreshnessDetail(params, freshness)
+	}
+	return strings.Join(params, "; ")
+}
+
+// appendFreshnessDetail flags lifetimes the proxy computed itself rather than took from the origin.
+func appendFreshnessDetail(params []string, freshness valueobject.FreshnessSource) []string {
+	if freshness == valueobject.FreshnessHeuristic || freshness == valueobject.FreshnessAdaptive {
+		params = append(params, "detail="+string(freshness))
+	}
+	return params
+}
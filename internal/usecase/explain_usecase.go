@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+type ExplainUseCase struct {
+	TimeService      contract.ITimeService
+	CacheRepository  contract.ICacheRepository
+	OriginRepository contract.IOriginRepository
+	PolicyEvaluator  contract.IPolicyEvaluator
+	CachePolicy      entity.CachePolicy
+	KeyBuilder       contract.ICacheKeyBuilder
+	Logger           contract.ILogger
+}
+
+func NewExplainUseCase(timeService contract.ITimeService, cacheRepository contract.ICacheRepository, originRepository contract.IOriginRepository, policyEvaluator contract.IPolicyEvaluator, cachePolicy entity.CachePolicy, keyBuilder contract.ICacheKeyBuilder, logger contract.ILogger) contract.IExplainUseCase {
+	return &ExplainUseCase{
+		TimeService:      timeService,
+		CacheRepository:  cacheRepository,
+		OriginRepository: originRepository,
+		PolicyEvaluator:  policyEvaluator,
+		CachePolicy:      cachePolicy,
+		KeyBuilder:       keyBuilder,
+		Logger:           logger,
+	}
+}
+
+func (uc *ExplainUseCase) Explain(ctx context.Context, req entity.RequestModel, fetch bool) entity.CacheExplanation {
+	key := uc.KeyBuilder.Build(req)
+	explanation := entity.CacheExplanation{
+		Key:    key,
+		Bypass: uc.PolicyEvaluator.ShouldBypass(req, uc.CachePolicy),
+	}
+
+	// a dry run must not count as a use of the entry
+	entry, found, err := uc.CacheRepository.Peek(ctx, key)
+	if err != nil {
+		uc.Logger.Error(ctx, "Cache Peek error", valueobject.LogField{Key: "error", Value: err.Error()}, valueobject.LogField{Key: "url", Value: key.NormalizedURL})
+	}
+	if found {
+		explanation.State = "fresh"
+		if entry.ExpiresAt <= uc.TimeService.NowUnix() {
+			explanation.State = "stale"
+		}
+		explanation.StoredAt = entry.StoredAt
+		explanation.ExpiresAt = entry.ExpiresAt
+		explanation.StaleUntil = entry.StaleUntil
+		explanation.Freshness = entry.Freshness
+	}
+
+	meta, known, err := uc.CacheRepository.GetMetadata(ctx, key)
+	if err != nil {
+		uc.Logger.Error(ctx, "Cache GetMetadata error", valueobject.LogField{Key: "error", Value: err.Error()}, valueobject.LogField{Key: "url", Value: key.NormalizedURL})
+	}
+	if known {
+		explanation.AdaptiveTTL = meta.TTL
+		explanation.BackoffUntil = meta.BackoffUntil
+	}
+
+	var resp entity.ResponseModel
+	switch {
+	case found:
+		resp = entry.Payload
+		explanation.Source = "cache"
+	case fetch:
+		resp, err = uc.OriginRepository.Fetch(ctx, req)
+		if err != nil {
+			explanation.FetchError = err.Error()
+			return explanation
+		}
+		explanation.Source = "origin"
+	default:
+		return explanation
+	}
+	explanation.OriginStatus = resp.Status
+
+	decision := uc.PolicyEvaluator.Evaluate(resp, req, uc.CachePolicy)
+	if decision.Cacheable && decision.Freshness == valueobject.FreshnessDefault && uc.CachePolicy.DefaultTTL.Adaptive {
+		decision.Freshness = valueobject.FreshnessAdaptive
+		decision.Warnings = append(decision.Warnings, "adaptive TTL is enabled: the lifetime grows while the content is unchanged and shrinks when it changes")
+		if known && meta.TTL > 0 {
+			decision.TTL = meta.TTL
+			decision.ExpiresAt = uc.TimeService.NowUnix() + int64(meta.TTL.Seconds())
+		}
+	}
+	explanation.Decision = &decision
+	return explanation
+}
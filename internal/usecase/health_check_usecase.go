@@ -11,13 +11,16 @@ type HealthCheckUseCase struct {
 	OriginRepository contract.IOriginRepository
 	CacheRepository contract.ICacheRepository
 	Logger contract.ILogger
+	// ReadinessGates hold readiness back until startup work such as cache warm-up is done.
+	ReadinessGates []contract.IReadinessGate
 }
 
-func NewHealthCheckUseCase(Logger contract.ILogger, orignrepo contract.IOriginRepository, cacherepo contract.ICacheRepository) contract.IHealthCheckUseCase {
+func NewHealthCheckUseCase(Logger contract.ILogger, orignrepo contract.IOriginRepository, cacherepo contract.ICacheRepository, gates ...contract.IReadinessGate) contract.IHealthCheckUseCase {
 	return &HealthCheckUseCase{
 		Logger: Logger,
 		OriginRepository: orignrepo,
 		CacheRepository: cacherepo,
+		ReadinessGates: gates,
 	}
 }
 
@@ -30,6 +33,12 @@ func (uc *HealthCheckUseCase) Readiness(ctx context.Context) error {
 		uc.Logger.Error(ctx, "Cache repository health check failed: %v", valueobject.LogField{Key: "Error: ", Value: err.Error()})
 		return err
 	}
+	for _, gate := range uc.ReadinessGates {
+		if err := gate.Ready(); err != nil {
+			uc.Logger.Info(ctx, "Not ready yet", valueobject.LogField{Key: "reason", Value: err.Error()})
+			return err
+		}
+	}
 	uc.Logger.Info(ctx, "Readyness check passed")
 	return nil
 }
@@ -2,137 +2,121 @@ package usecase
 
 import (
 	"context"
-	"net/url"
-	"path"
-	"sort"
-	"strings"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
 	"time"
 
 	"github.com/mikiasgoitom/RevProx/internal/contract"
 	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	domainservice "github.com/mikiasgoitom/RevProx/internal/domain/service"
 	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
 )
 
 type ProxyUseCase struct {
-	TimeService contract.ITimeService
-	CacheRepository contract.ICacheRepository
+	TimeService       contract.ITimeService
+	CacheRepository   contract.ICacheRepository
 	PrometheusMetrics contract.IMetricsAdapter
-	Logger contract.ILogger
-	OriginRepository contract.IOriginRepository
-	PolicyEvaluator contract.IPolicyEvaluator
-	CachePolicy entity.CachePolicy
+	Logger            contract.ILogger
+	OriginRepository  contract.IOriginRepository
+	PolicyEvaluator   contract.IPolicyEvaluator
+	CachePolicy       entity.CachePolicy
+	KeyBuilder        contract.ICacheKeyBuilder
+	Tracer            contract.ITracer
+	HotKeys           contract.IHotKeyTracker
+	HotClients        contract.IHotKeyTracker
 }
 
-	func NewProxyUsecase(timeService contract.ITimeService, cacheRepository contract.ICacheRepository, prometheusMetrics contract.IMetricsAdapter, logger contract.ILogger, originRepository contract.IOriginRepository, PolicyEvaluator contract.IPolicyEvaluator, cachePolicy entity.CachePolicy) contract.IProxyUseCase {
+func NewProxyUsecase(timeService contract.ITimeService, cacheRepository contract.ICacheRepository, prometheusMetrics contract.IMetricsAdapter, logger contract.ILogger, originRepository contract.IOriginRepository, PolicyEvaluator contract.IPolicyEvaluator, cachePolicy entity.CachePolicy, keyBuilder contract.ICacheKeyBuilder, tracer contract.ITracer, hotKeys contract.IHotKeyTracker, hotClients contract.IHotKeyTracker) contract.IProxyUseCase {
 	return &ProxyUseCase{
-		TimeService: timeService,
-		CacheRepository: cacheRepository,
+		TimeService:       timeService,
+		CacheRepository:   cacheRepository,
 		PrometheusMetrics: prometheusMetrics,
-		Logger: logger,
-		OriginRepository: originRepository,
-		PolicyEvaluator: PolicyEvaluator,
-		CachePolicy: cachePolicy,
+		Logger:            logger,
+		OriginRepository:  originRepository,
+		PolicyEvaluator:   PolicyEvaluator,
+		CachePolicy:       cachePolicy,
+		KeyBuilder:        keyBuilder,
+		Tracer:            tracer,
+		HotKeys:           hotKeys,
+		HotClients:        hotClients,
 	}
 }
 
-func normalizeURL(req_url *url.URL) string {
-	if req_url == nil {
-		return ""
-	}
-	u := *req_url
-	u.Fragment = ""
-	
-	host := strings.ToLower(u.Hostname())
-	port := u.Port()
-	if port != "" {
-		if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
-			u.Host = host
-		} else {
-			u.Host = host + ":" + port
-		}
-	}
-
-	if u.Path == "" {
-		u.Path = "/"
-	} else {
-		u.Path = path.Clean(u.Path)
-		if !strings.HasPrefix(u.Path, "/") {
-			u.Path = "/" + u.Path
-		}
-	}
-	queryParams := u.Query()
-	if len(queryParams) == 0 {
-		u.RawQuery = ""
-		return u.String()
-	}
-	keys := make([]string, 0, len(queryParams))
-	for k := range queryParams {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
+func (uc *ProxyUseCase) ServeProxyRequest(ctx context.Context, req entity.RequestModel) (entity.ResponseModel, error) {
+	// Build CacheKey from the configured key policy.
+	cacheKey := uc.KeyBuilder.Build(req)
 
-	out := url.Values{}
-	for _, k := range keys {
-		vals := queryParams[k]
-		sort.Strings(vals)
-		for _, v := range vals {
-			out.Add(k, v)
-		}
+	resp, err := uc.serve(ctx, req, cacheKey)
+	if err == nil && !req.Warmup {
+		uc.trackHotKey(cacheKey, req, resp)
 	}
-	u.RawQuery = out.Encode()
-	return u.String()
+	return resp, err
 }
-func (uc *ProxyUseCase) ServeProxyRequest(ctx context.Context, req entity.RequestModel) (entity.ResponseModel, error) {
+
+func (uc *ProxyUseCase) serve(ctx context.Context, req entity.RequestModel, cacheKey valueobject.CacheKey) (entity.ResponseModel, error) {
 	// Start timing.
-	startTime := uc.TimeService.NowUnix()
-	
-    // Normalize URL (sort query params, drop fragment) -> normalizedURL.
-	normalizedURL := normalizeURL(req.URL)
+	startTime := uc.TimeService.Monotonic()
+	normalizedURL := cacheKey.NormalizedURL
 
-    // Build CacheKey {Method, NormalizedURL}.
-	cacheKey := valueobject.CacheKey{
-		Method: req.Method,
-		NormalizedURL: normalizedURL,
+	// Cache lookup, record cache latency; inc hit/miss metrics.
+	// Requests matching a bypass rule never read from the cache.
+	var cacheValRetrieved entity.CacheEntry
+	var found bool
+	var err error
+	bypass := uc.PolicyEvaluator.ShouldBypass(req, uc.CachePolicy)
+	backoff := canBackoff(req, bypass)
+	if !bypass {
+		lookupCtx, lookupSpan := uc.Tracer.Start(ctx, "cache.lookup", valueobject.SpanKindInternal, valueobject.LogField{Key: "cache.key", Value: normalizedURL})
+		cacheValRetrieved, found, err = uc.CacheRepository.Get(lookupCtx, cacheKey)
+		lookupSpan.SetAttributes(valueobject.LogField{Key: "cache.found", Value: found})
+		if err != nil {
+			lookupSpan.RecordError(err)
+		}
+		lookupSpan.End()
 	}
 
-    // Cache lookup, record cache latency; inc hit/miss metrics.
-	cacheValRetrieved, found, err := uc.CacheRepository.Get(ctx, cacheKey)
-	
 	if err != nil {
 		uc.Logger.Error(ctx, "Cache Get error", valueobject.LogField{Key: "error", Value: err.Error()}, valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL})
 		return entity.ResponseModel{}, err
 	}
 
-	cacheLatency := uc.TimeService.NowUnix() - startTime
-	if found {
-		err = uc.PrometheusMetrics.IncHit(ctx)
-		if err != nil {
-			uc.Logger.Error(ctx, "Metrics IncHit error", valueobject.LogField{Key: "error", Value: err.Error()})
-		}
-		err = uc.PrometheusMetrics.RecordCacheLatency(ctx, time.Duration(cacheLatency)*time.Millisecond)
-		if err != nil {
-			uc.Logger.Error(ctx, "Metrics RecordCacheLatency error", valueobject.LogField{Key: "error", Value: err.Error()})
-		}
-		uc.Logger.Info(ctx, "Cache hit", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "latency_ms", Value: cacheLatency})
-		
+	cacheLatency := uc.TimeService.Since(startTime)
+	// Entries past their expiry are only kept for revalidation and must not be served as-is.
+	stale := found && cacheValRetrieved.ExpiresAt <= uc.TimeService.NowUnix()
+	if found && !stale {
+		uc.recordLookup(ctx, req, true, cacheLatency)
+		uc.Logger.Info(ctx, "Cache hit", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "latency_ms", Value: valueobject.Millis(cacheLatency)})
+
 		resp := cacheValRetrieved.Payload
-		uc.Logger.Info(ctx, "Response served from cache", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "latency_ms", Value: cacheLatency})
-		return resp, nil		
+		resp.Headers = resp.Headers.Clone()
+		resp.Headers.Set(cacheStatusHeader, cacheStatusHit(cacheValRetrieved, uc.TimeService.NowUnix()))
+		resp.CacheOutcome = valueobject.CacheOutcomeHit
+		resp.ProxyTiming = entity.ProxyTiming{CacheLookup: cacheLatency}
+		resp.ProxyTiming.Total = uc.recordTotalLatency(ctx, req, startTime)
+		uc.Logger.Info(ctx, "Response served from cache", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "latency_ms", Value: valueobject.Millis(cacheLatency)})
+		return resp, nil
+	} else if bypass {
+		uc.Logger.Info(ctx, "Cache bypassed", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL})
 	} else {
-		err = uc.PrometheusMetrics.IncMiss(ctx)
-		if err != nil {
-			uc.Logger.Error(ctx, "Metrics IncMiss error", valueobject.LogField{Key: "error", Value: err.Error()})
-		}
-		err = uc.PrometheusMetrics.RecordCacheLatency(ctx, time.Duration(cacheLatency)*time.Millisecond)
-		if err != nil {
-			uc.Logger.Error(ctx, "Metrics RecordCacheLatency error", valueobject.LogField{Key: "error", Value: err.Error()})
-		}
-		uc.Logger.Info(ctx, "Cache miss", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "latency_ms", Value: cacheLatency})
+		uc.recordLookup(ctx, req, false, cacheLatency)
+		uc.Logger.Info(ctx, "Cache miss", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "latency_ms", Value: valueobject.Millis(cacheLatency)}, valueobject.LogField{Key: "stale", Value: stale})
 
+		// The origin asked us to back off for this key: answer without contacting it.
+		if backoff {
+			if resp, ok := uc.serveDuringBackoff(ctx, cacheKey, cacheValRetrieved, stale); ok {
+				resp.ProxyTiming = entity.ProxyTiming{CacheLookup: cacheLatency, Total: uc.recordTotalLatency(ctx, req, startTime)}
+				return resp, nil
+			}
+		}
 	}
 
-    // Prepare origin request (preserve headers; add X-Forwarded-For, X-Forwarded-Host, X-Forwarded-Proto).
+	// Prepare origin request (preserve headers; add X-Request-ID, X-Forwarded-For, X-Forwarded-Host, X-Forwarded-Proto).
 	originHeaders := req.Headers.Clone()
+	if req.ID != "" {
+		originHeaders.Set(valueobject.RequestIDHeader, req.ID)
+	}
 	if req.ClientIP != "" {
 		originHeaders.Del("X-Forwarded-For")
 		originHeaders.Add("X-Forwarded-For", req.ClientIP)
@@ -145,52 +129,283 @@ func (uc *ProxyUseCase) ServeProxyRequest(ctx context.Context, req entity.Reques
 		originHeaders.Add("X-Forwarded-Proto", req.URL.Scheme)
 	}
 
-    // Origin.Fetch, record upstream latency
-	originFetchStartTime := uc.TimeService.NowUnix()
+	// A stale entry is revalidated with a conditional request unless the client sent its own.
+	conditional := stale && addConditionalHeaders(originHeaders, cacheValRetrieved.Payload.Headers)
+
+	// Origin.Fetch, record upstream latency
+	originFetchStartTime := uc.TimeService.Monotonic()
 	originReq := req
 	originReq.Headers = originHeaders
-	resp, err := uc.OriginRepository.Fetch(ctx, originReq)
+	resp, err := uc.fetchFromOrigin(ctx, originReq)
 	if err != nil {
-		uc.Logger.Fatal(ctx, "Origin Fetch error", valueobject.LogField{Key: "error", Value: err.Error()}, valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL})
+		if stale && uc.CachePolicy.StaleIfOverloaded && isOverloaded(err) {
+			staleResp := uc.serveStale(ctx, cacheValRetrieved, "overloaded")
+			staleResp.ProxyTiming = entity.ProxyTiming{
+				CacheLookup: cacheLatency,
+				OriginFetch: uc.TimeService.Since(originFetchStartTime),
+				Total:       uc.recordTotalLatency(ctx, req, startTime),
+			}
+			return staleResp, nil
+		}
+		uc.Logger.Error(ctx, "Origin Fetch error", valueobject.LogField{Key: "error", Value: err.Error()}, valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL})
 		return entity.ResponseModel{}, err
 	}
-	originFetchLatency := uc.TimeService.NowUnix() - originFetchStartTime
-	err = uc.PrometheusMetrics.RecordUpstreamLatency(ctx, time.Duration(originFetchLatency)*time.Millisecond)
+	originStatus := resp.Status
+	// Recorded before any early return so that every origin answer shows up in the metrics.
+	originFetchLatency := uc.TimeService.Since(originFetchStartTime)
+	err = uc.PrometheusMetrics.RecordUpstreamLatency(ctx, originFetchLatency)
 	if err != nil {
 		uc.Logger.Error(ctx, "Metrics RecordUpstreamLatency error", valueobject.LogField{Key: "error", Value: err.Error()})
 	}
-	uc.Logger.Info(ctx, "Origin fetch successful", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "latency_ms", Value: originFetchLatency})
+	for _, phase := range resp.Timing.Phases() {
+		if err = uc.PrometheusMetrics.RecordUpstreamPhase(ctx, phase.Name, phase.Duration); err != nil {
+			uc.Logger.Error(ctx, "Metrics RecordUpstreamPhase error", valueobject.LogField{Key: "error", Value: err.Error()})
+		}
+	}
+	uc.Logger.Info(ctx, "Origin fetch successful", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "status", Value: originStatus}, valueobject.LogField{Key: "latency_ms", Value: valueobject.Millis(originFetchLatency)}, valueobject.LogField{Key: "ttfb_ms", Value: valueobject.Millis(resp.Timing.TTFB)})
+
+	if retryAfter, ok := uc.retryAfter(resp); ok && backoff {
+		uc.startBackoff(ctx, cacheKey, resp, retryAfter)
+		if stale {
+			staleResp := uc.serveStale(ctx, cacheValRetrieved, "backoff")
+			staleResp.ProxyTiming = entity.ProxyTiming{
+				CacheLookup: cacheLatency,
+				OriginFetch: originFetchLatency,
+				Total:       uc.recordTotalLatency(ctx, req, startTime),
+			}
+			return staleResp, nil
+		}
+	}
+	revalidated := conditional && resp.Status == http.StatusNotModified
+	if revalidated {
+		resp = refreshStoredResponse(cacheValRetrieved.Payload, resp)
+		uc.Logger.Info(ctx, "Stale entry revalidated", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL})
+	}
 
-    // 7. Evaluate cacheability
-	cacheable, ttl := uc.PolicyEvaluator.Evaluate(resp, req, uc.CachePolicy)
+	// 7. Evaluate cacheability
+	decision := uc.PolicyEvaluator.Evaluate(resp, req, uc.CachePolicy)
+	cacheable, ttl, freshness := decision.Cacheable, decision.ExpiresAt, decision.Freshness
+	if cacheable && freshness == valueobject.FreshnessDefault && uc.CachePolicy.DefaultTTL.Adaptive {
+		ttl = uc.adaptTTL(ctx, cacheKey, resp.Body, revalidated)
+		freshness = valueobject.FreshnessAdaptive
+	}
 	resp.Cacheable = cacheable
-	uc.Logger.Info(ctx, "Cache policy evaluated", valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "cacheable", Value: cacheable}, valueobject.LogField{Key: "ttl_seconds", Value: time.Unix(ttl, 0)})
-    
+	uc.Logger.Info(ctx, "Cache policy evaluated", valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "cacheable", Value: cacheable}, valueobject.LogField{Key: "ttl_seconds", Value: time.Unix(ttl, 0)}, valueobject.LogField{Key: "freshness", Value: freshness}, valueobject.LogField{Key: "rule", Value: decision.Rule}, valueobject.LogField{Key: "reason", Value: decision.Reason}, valueobject.LogField{Key: "warnings", Value: decision.Warnings})
+
 	// If cacheable and ttlSeconds > 0: build CacheEntry then Cache.Set(ctx, entry)
-	if cacheable && ttl > 0{
+	stored := false
+	var cacheWriteLatency time.Duration
+	if cacheable && ttl > 0 {
+		payload := resp
+		payload.Timing = entity.UpstreamTiming{}
+		if len(resp.Headers.Values("Set-Cookie")) > 0 {
+			// only reachable in strip mode: the client still gets its cookie, the stored copy does not
+			payload.Headers = resp.Headers.Clone()
+			payload.Headers.Del("Set-Cookie")
+		}
 		newCacheEntry := entity.CacheEntry{
-			Key: cacheKey,
-			Payload: resp,
+			Key:       cacheKey,
+			Payload:   payload,
 			ExpiresAt: ttl,
-			StoredAt: uc.TimeService.NowUnix(),
+			StoredAt:  uc.TimeService.NowUnix(),
+			Freshness: freshness,
+		}
+		if uc.CachePolicy.RevalidateWindow > 0 {
+			newCacheEntry.StaleUntil = ttl + int64(uc.CachePolicy.RevalidateWindow.Seconds())
 		}
-		if err = uc.CacheRepository.Set(ctx, newCacheEntry); err != nil {
-			uc.Logger.Error(ctx, "Cache Set error", valueobject.LogField{Key: "error", Value: err.Error()}, valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL} )
+		cacheWriteStartTime := uc.TimeService.Monotonic()
+		writeCtx, writeSpan := uc.Tracer.Start(ctx, "cache.write", valueobject.SpanKindInternal, valueobject.LogField{Key: "cache.key", Value: normalizedURL}, valueobject.LogField{Key: "cache.ttl", Value: ttl - uc.TimeService.NowUnix()})
+		if err = uc.CacheRepository.Set(writeCtx, newCacheEntry); err != nil {
+			writeSpan.RecordError(err)
+			uc.Logger.Error(ctx, "Cache Set error", valueobject.LogField{Key: "error", Value: err.Error()}, valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL})
+		} else {
+			stored = true
 		}
-		uc.Logger.Info(ctx, "Response cached", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "ttl_seconds", Value: time.Unix(ttl, 0)} )
+		writeSpan.End()
+		cacheWriteLatency = uc.TimeService.Since(cacheWriteStartTime)
+		uc.Logger.Info(ctx, "Response cached", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "ttl_seconds", Value: time.Unix(ttl, 0)})
+	}
+	resp.Headers = resp.Headers.Clone()
+	fwd := "miss"
+	resp.CacheOutcome = valueobject.CacheOutcomeMiss
+	if stale {
+		fwd = "stale"
+	} else if bypass {
+		fwd = "bypass"
+		resp.CacheOutcome = valueobject.CacheOutcomeBypass
+	}
+	if revalidated {
+		resp.CacheOutcome = valueobject.CacheOutcomeRevalidated
 	}
+	resp.Headers.Set(cacheStatusHeader, cacheStatusForward(fwd, originStatus, stored, ttl-uc.TimeService.NowUnix(), freshness))
 
-    // Update total latency metrics.
-	totalLatency := uc.TimeService.NowUnix() - startTime
-	err = uc.PrometheusMetrics.RecordTotalLatency(ctx, time.Duration(totalLatency)*time.Millisecond)
-	if err != nil {
-		uc.Logger.Error(ctx, "Metrics RecordTotalLatency error", valueobject.LogField{Key: "error", Value: err.Error()})
+	// Update total latency metrics.
+	totalLatency := uc.recordTotalLatency(ctx, req, startTime)
+	resp.ProxyTiming = entity.ProxyTiming{
+		CacheLookup: cacheLatency,
+		OriginFetch: originFetchLatency,
+		CacheWrite:  cacheWriteLatency,
+		Total:       totalLatency,
+	}
+	if bypass {
+		resp.ProxyTiming.CacheLookup = 0
 	}
 
-    // log summary
-	uc.Logger.Info(ctx, "Request served from origin", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "cacheable", Value: cacheable}, valueobject.LogField{Key: "total_latency_ms", Value: totalLatency})
+	// log summary
+	uc.Logger.Info(ctx, "Request served from origin", valueobject.LogField{Key: "method", Value: req.Method}, valueobject.LogField{Key: "url", Value: normalizedURL}, valueobject.LogField{Key: "cacheable", Value: cacheable}, valueobject.LogField{Key: "total_latency_ms", Value: valueobject.Millis(totalLatency)})
 
-    // Return ResponseModel
+	// Return ResponseModel
 	return resp, nil
 
-}
\ No newline at end of file
+}
+
+// trackHotKey feeds the served response into the hot key and hot client rankings.
+func (uc *ProxyUseCase) trackHotKey(key valueobject.CacheKey, req entity.RequestModel, resp entity.ResponseModel) {
+	sample := entity.HotKeySample{
+		Requests:   1,
+		Bytes:      uint64(len(resp.Body)),
+		OriginTime: resp.Timing.Total,
+	}
+	if resp.CacheOutcome == valueobject.CacheOutcomeMiss {
+		sample.Misses = 1
+	}
+	now := uc.TimeService.NowUnix()
+	uc.HotKeys.Record(key.String(), sample, now)
+	if req.ClientIP != "" {
+		uc.HotClients.Record(req.ClientIP, sample, now)
+	}
+}
+
+// addConditionalHeaders turns the origin request into a revalidation of stored using its
+// validators. It reports false when the client already sent conditional headers or when
+// stored carries no validators.
+func addConditionalHeaders(originHeaders http.Header, stored http.Header) bool {
+	if originHeaders.Get("If-None-Match") != "" || originHeaders.Get("If-Modified-Since") != "" {
+		return false
+	}
+	added := false
+	if etag := stored.Get("ETag"); etag != "" {
+		originHeaders.Set("If-None-Match", etag)
+		added = true
+	}
+	if lastModified := stored.Get("Last-Modified"); lastModified != "" {
+		originHeaders.Set("If-Modified-Since", lastModified)
+		added = true
+	}
+	return added
+}
+
+// refreshStoredResponse applies the headers of a 304 to the stored response (RFC 9111 section 4.3.4).
+func refreshStoredResponse(stored entity.ResponseModel, notModified entity.ResponseModel) entity.ResponseModel {
+	headers := stored.Headers.Clone()
+	for key, values := range notModified.Headers {
+		if key == "Content-Length" {
+			continue
+		}
+		headers[key] = values
+	}
+	stored.Headers = headers
+	stored.ID = notModified.ID
+	stored.GeneratedAt = notModified.GeneratedAt
+	stored.Timing = notModified.Timing
+	return stored
+}
+
+// adaptTTL lengthens the key's TTL when the origin content is unchanged since the last fetch
+// and shortens it when it changed, persisting the history in the cache metadata. It returns
+// the new absolute expiry.
+func (uc *ProxyUseCase) adaptTTL(ctx context.Context, key valueobject.CacheKey, body []byte, revalidated bool) int64 {
+	now := uc.TimeService.NowUnix()
+	meta, known, err := uc.CacheRepository.GetMetadata(ctx, key)
+	if err != nil {
+		uc.Logger.Error(ctx, "Cache GetMetadata error", valueobject.LogField{Key: "error", Value: err.Error()}, valueobject.LogField{Key: "url", Value: key.NormalizedURL})
+		known = false
+	}
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:])
+	changed := !revalidated && meta.BodyHash != hash
+
+	meta.Key = key
+	meta.BodyHash = hash
+	meta = domainservice.AdjustAdaptiveTTL(uc.CachePolicy.DefaultTTL, meta, known, changed, now)
+	// keep the history long enough to outlive the entry it describes
+	meta.ExpiresAt = now + int64(2*max(meta.TTL, uc.CachePolicy.DefaultTTL.Max).Seconds())
+	if err := uc.CacheRepository.SetMetadata(ctx, meta); err != nil {
+		uc.Logger.Error(ctx, "Cache SetMetadata error", valueobject.LogField{Key: "error", Value: err.Error()}, valueobject.LogField{Key: "url", Value: key.NormalizedURL})
+	}
+	uc.Logger.Info(ctx, "Adaptive TTL adjusted", valueobject.LogField{Key: "url", Value: key.NormalizedURL}, valueobject.LogField{Key: "changed", Value: changed}, valueobject.LogField{Key: "ttl", Value: meta.TTL.String()})
+	return now + int64(meta.TTL.Seconds())
+}
+
+// fetchFromOrigin wraps the origin call with tracing, trace context propagation and
+// in-flight and error accounting.
+func (uc *ProxyUseCase) fetchFromOrigin(ctx context.Context, req entity.RequestModel) (entity.ResponseModel, error) {
+	ctx, span := uc.Tracer.Start(ctx, "origin.fetch", valueobject.SpanKindClient, valueobject.LogField{Key: "http.request.method", Value: req.Method})
+	defer span.End()
+	uc.Tracer.Inject(ctx, req.Headers)
+
+	if err := uc.PrometheusMetrics.IncOriginInFlight(ctx); err != nil {
+		uc.Logger.Error(ctx, "Metrics IncOriginInFlight error", valueobject.LogField{Key: "error", Value: err.Error()})
+	}
+	resp, err := uc.OriginRepository.Fetch(ctx, req)
+	if err := uc.PrometheusMetrics.DecOriginInFlight(ctx); err != nil {
+		uc.Logger.Error(ctx, "Metrics DecOriginInFlight error", valueobject.LogField{Key: "error", Value: err.Error()})
+	}
+
+	kind := ""
+	var originErr *entity.OriginError
+	if errors.As(err, &originErr) {
+		kind = originErr.Kind
+	} else if err != nil {
+		kind = entity.OriginErrorOther
+	} else if resp.Status >= http.StatusInternalServerError {
+		kind = entity.OriginErrorStatus5xx
+	}
+	if err != nil {
+		span.RecordError(err)
+	} else {
+		span.SetAttributes(valueobject.LogField{Key: "http.response.status_code", Value: resp.Status})
+	}
+	if kind != "" {
+		span.SetAttributes(valueobject.LogField{Key: "error.type", Value: kind})
+		if err := uc.PrometheusMetrics.RecordOriginError(ctx, kind); err != nil {
+			uc.Logger.Error(ctx, "Metrics RecordOriginError error", valueobject.LogField{Key: "error", Value: err.Error()})
+		}
+	}
+	return resp, err
+}
+
+// isOverloaded reports whether err comes from the origin concurrency limit shedding the request.
+func isOverloaded(err error) bool {
+	var originErr *entity.OriginError
+	return errors.As(err, &originErr) && originErr.Kind == entity.OriginErrorOverloaded
+}
+
+func (uc *ProxyUseCase) recordTotalLatency(ctx context.Context, req entity.RequestModel, startTime time.Time) time.Duration {
+	totalLatency := uc.TimeService.Since(startTime)
+	if req.Warmup {
+		return totalLatency
+	}
+	if err := uc.PrometheusMetrics.RecordTotalLatency(ctx, totalLatency); err != nil {
+		uc.Logger.Error(ctx, "Metrics RecordTotalLatency error", valueobject.LogField{Key: "error", Value: err.Error()})
+	}
+	return totalLatency
+}
+
+// recordLookup counts a cache hit or miss and its latency. Warm-up requests are left out so
+// that they do not drag down the hit ratio.
+func (uc *ProxyUseCase) recordLookup(ctx context.Context, req entity.RequestModel, hit bool, latency time.Duration) {
+	if req.Warmup {
+		return
+	}
+	if hit {
+		if err := uc.PrometheusMetrics.IncHit(ctx); err != nil {
+			uc.Logger.Error(ctx, "Metrics IncHit error", valueobject.LogField{Key: "error", Value: err.Error()})
+		}
+	} else if err := uc.PrometheusMetrics.IncMiss(ctx); err != nil {
+		uc.Logger.Error(ctx, "Metrics IncMiss error", valueobject.LogField{Key: "error", Value: err.Error()})
+	}
+	if err := uc.PrometheusMetrics.RecordCacheLatency(ctx, latency); err != nil {
+		uc.Logger.Error(ctx, "Metrics RecordCacheLatency error", valueobject.LogField{Key: "error", Value: err.Error()})
+	}
+}
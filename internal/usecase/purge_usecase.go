@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+type PurgeUseCase struct {
+	CacheRepo contract.ICacheRepository
+	Logger    contract.ILogger
+}
+
+func NewPurgeUseCase(cacheRepo contract.ICacheRepository, logger contract.ILogger) contract.IPurgeUseCase {
+	return &PurgeUseCase{CacheRepo: cacheRepo, Logger: logger}
+}
+
+func (uc *PurgeUseCase) Purge(ctx context.Context, key string) (int, error) {
+	var entries []entity.CacheEntryInfo
+	if info, ok := uc.CacheRepo.Lookup(ctx, key, false); ok {
+		entries = append(entries, info)
+	}
+	return uc.purge(ctx, entries, valueobject.LogField{Key: "key", Value: key})
+}
+
+func (uc *PurgeUseCase) PurgePrefix(ctx context.Context, prefix string) (int, error) {
+	page := uc.CacheRepo.List(ctx, entity.CacheEntryQuery{Prefix: prefix})
+	return uc.purge(ctx, page.Entries, valueobject.LogField{Key: "prefix", Value: prefix})
+}
+
+func (uc *PurgeUseCase) purge(ctx context.Context, entries []entity.CacheEntryInfo, target valueobject.LogField) (int, error) {
+	purged := 0
+	var errs []error
+	for _, entry := range entries {
+		removed, err := uc.CacheRepo.Delete(ctx, entry.Key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if removed {
+			purged++
+		}
+	}
+	uc.Logger.Info(ctx, "Cache purged", target, valueobject.LogField{Key: "purged", Value: purged})
+	return purged, errors.Join(errs...)
+}
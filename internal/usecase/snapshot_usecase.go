@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"io"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+type SnapshotUseCase struct {
+	CacheRepo   contract.ICacheRepository
+	Archive     contract.ISnapshotArchive
+	TimeService contract.ITimeService
+	Logger      contract.ILogger
+}
+
+func NewSnapshotUseCase(cacheRepo contract.ICacheRepository, archive contract.ISnapshotArchive, timeService contract.ITimeService, logger contract.ILogger) contract.ISnapshotUseCase {
+	return &SnapshotUseCase{CacheRepo: cacheRepo, Archive: archive, TimeService: timeService, Logger: logger}
+}
+
+func (uc *SnapshotUseCase) Export(ctx context.Context, w io.Writer) (int, error) {
+	entries := uc.CacheRepo.Entries(ctx)
+	info := entity.SnapshotInfo{CreatedAt: uc.TimeService.Now(), Entries: len(entries)}
+	if err := uc.Archive.Write(w, info, entries); err != nil {
+		return 0, err
+	}
+	uc.Logger.Info(ctx, "Cache snapshot exported", valueobject.LogField{Key: "entries", Value: len(entries)})
+	return len(entries), nil
+}
+
+// Import keeps entries that can still be served or revalidated: an entry past ExpiresAt but
+// within its stale window is loaded, one past both is skipped. The cache may still turn away
+// or evict an entry it accepted, so Loaded counts the entries found in it once the archive
+// has been read.
+func (uc *SnapshotUseCase) Import(ctx context.Context, r io.Reader) (entity.SnapshotImport, error) {
+	var result entity.SnapshotImport
+	now := uc.TimeService.Now().Unix()
+	stored := make(map[valueobject.CacheKey]struct{})
+	info, err := uc.Archive.Read(r, func(entry entity.CacheEntry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if max(entry.ExpiresAt, entry.StaleUntil) <= now {
+			result.Expired++
+			return nil
+		}
+		if err := uc.CacheRepo.Set(ctx, entry); err != nil {
+			result.Failed++
+			return nil
+		}
+		stored[entry.Key] = struct{}{}
+		return nil
+	})
+	for key := range stored {
+		if _, found, _ := uc.CacheRepo.Peek(ctx, key); found {
+			result.Loaded++
+		} else {
+			result.Dropped++
+		}
+	}
+	result.Archive = info
+	fields := []valueobject.LogField{
+		{Key: "created_at", Value: info.CreatedAt},
+		{Key: "loaded", Value: result.Loaded},
+		{Key: "dropped", Value: result.Dropped},
+		{Key: "expired", Value: result.Expired},
+		{Key: "failed", Value: result.Failed},
+	}
+	if err != nil {
+		uc.Logger.Error(ctx, "Cache snapshot import failed", append(fields, valueobject.LogField{Key: "error", Value: err.Error()})...)
+		return result, err
+	}
+	uc.Logger.Info(ctx, "Cache snapshot imported", fields...)
+	return result, nil
+}
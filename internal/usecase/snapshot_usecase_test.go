@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+	"github.com/mikiasgoitom/RevProx/internal/infrastructure/snapshot"
+)
+
+// admittingCache keeps entries in a map, silently dropping the keys it does not admit the
+// way ristretto's admission policy does; the other methods are not used by snapshots.
+type admittingCache struct {
+	contract.ICacheRepository
+	entries  map[valueobject.CacheKey]entity.CacheEntry
+	rejected map[valueobject.CacheKey]bool
+}
+
+func (c *admittingCache) Set(ctx context.Context, entry entity.CacheEntry) error {
+	if !c.rejected[entry.Key] {
+		c.entries[entry.Key] = entry
+	}
+	return nil
+}
+
+func (c *admittingCache) Peek(ctx context.Context, key valueobject.CacheKey) (entity.CacheEntry, bool, error) {
+	entry, found := c.entries[key]
+	return entry, found, nil
+}
+
+func TestSnapshotImport(t *testing.T) {
+	now := time.Unix(1000, 0)
+	entry := func(path string, expiresAt, staleUntil int64) entity.CacheEntry {
+		return entity.CacheEntry{
+			Key:        valueobject.CacheKey{Method: "GET", NormalizedURL: path},
+			Payload:    entity.ResponseModel{Status: http.StatusOK, Headers: http.Header{}, Body: []byte(path)},
+			ExpiresAt:  expiresAt,
+			StaleUntil: staleUntil,
+		}
+	}
+	fresh := entry("/fresh", 1060, 0)
+	stale := entry("/stale", 990, 1030)
+	expired := entry("/expired", 990, 1000)
+	rejected := entry("/rejected", 1060, 0)
+
+	var buf bytes.Buffer
+	archive := snapshot.NewGzipArchive()
+	if err := archive.Write(&buf, entity.SnapshotInfo{CreatedAt: now}, []entity.CacheEntry{fresh, stale, expired, rejected}); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := &admittingCache{
+		entries:  map[valueobject.CacheKey]entity.CacheEntry{},
+		rejected: map[valueobject.CacheKey]bool{rejected.Key: true},
+	}
+	uc := NewSnapshotUseCase(cache, archive, fixedTime{now}, discardLogger{})
+	result, err := uc.Import(context.Background(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Loaded != 2 || result.Dropped != 1 || result.Expired != 1 || result.Failed != 0 {
+		t.Fatalf("result = %+v, want 2 loaded, 1 dropped and 1 expired", result)
+	}
+	if result.Archive.Entries != 4 {
+		t.Errorf("archive entries = %d, want 4", result.Archive.Entries)
+	}
+	for _, e := range []entity.CacheEntry{fresh, stale} {
+		if _, found := cache.entries[e.Key]; !found {
+			t.Errorf("%s was not loaded", e.Key.NormalizedURL)
+		}
+	}
+	if _, found := cache.entries[expired.Key]; found {
+		t.Error("an entry past its stale window was loaded")
+	}
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// statsWindows are the sliding windows reported by the stats endpoint.
+var statsWindows = []time.Duration{time.Minute, 5 * time.Minute, time.Hour}
+
+type StatsUseCase struct {
+	CacheStats       contract.ICacheStatsProvider
+	MetricsWindow    contract.IMetricsWindow
+	TimeService      contract.ITimeService
+	HotKeyTracker    contract.IHotKeyTracker
+	HotClientTracker contract.IHotKeyTracker
+}
+
+func NewStatsUseCase(cacheStats contract.ICacheStatsProvider, metricsWindow contract.IMetricsWindow, timeService contract.ITimeService, hotKeys contract.IHotKeyTracker, hotClients contract.IHotKeyTracker) contract.IStatsUseCase {
+	return &StatsUseCase{
+		CacheStats:       cacheStats,
+		MetricsWindow:    metricsWindow,
+		TimeService:      timeService,
+		HotKeyTracker:    hotKeys,
+		HotClientTracker: hotClients,
+	}
+}
+
+func (uc *StatsUseCase) Stats(ctx context.Context) entity.StatsReport {
+	report := entity.StatsReport{
+		GeneratedAt: uc.TimeService.Now(),
+		Cache:       uc.CacheStats.Stats(ctx),
+		Windows:     make([]entity.Metrics, 0, len(statsWindows)),
+	}
+	for _, window := range statsWindows {
+		report.Windows = append(report.Windows, uc.MetricsWindow.Snapshot(window))
+	}
+	return report
+}
+
+func (uc *StatsUseCase) HotKeys(ctx context.Context, by valueobject.HotKeyDimension, limit int) []entity.HotKey {
+	return uc.HotKeyTracker.Top(by, limit)
+}
+
+func (uc *StatsUseCase) HotClients(ctx context.Context, by valueobject.HotKeyDimension, limit int) []entity.HotKey {
+	return uc.HotClientTracker.Top(by, limit)
+}
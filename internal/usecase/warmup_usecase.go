@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mikiasgoitom/RevProx/internal/contract"
+	"github.com/mikiasgoitom/RevProx/internal/domain/entity"
+	"github.com/mikiasgoitom/RevProx/internal/domain/valueobject"
+)
+
+// warmupUserAgent identifies warm-up requests in origin logs.
+const warmupUserAgent = "revprox-warmup/1"
+
+// errWarmupPending is reported by Ready until the first warm-up run has finished.
+var errWarmupPending = errors.New("cache warm-up has not finished")
+
+type WarmupUseCase struct {
+	ProxyUseCase contract.IProxyUseCase
+	Sources      []contract.IWarmupSource
+	TimeService  contract.ITimeService
+	Logger       contract.ILogger
+	// Concurrency bounds parallel fetches, Rate caps fetches per second (zero is unlimited)
+	// and MaxURLs caps a run (zero is unlimited).
+	Concurrency int
+	Rate        float64
+	MaxURLs     int
+	Headers     http.Header
+
+	mu        sync.Mutex
+	progress  entity.WarmupProgress
+	completed bool
+}
+
+func NewWarmupUseCase(proxyUC contract.IProxyUseCase, sources []contract.IWarmupSource, timeService contract.ITimeService, logger contract.ILogger, concurrency int, rate float64, maxURLs int, headers http.Header) contract.IWarmupUseCase {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &WarmupUseCase{
+		ProxyUseCase: proxyUC,
+		Sources:      sources,
+		TimeService:  timeService,
+		Logger:       logger,
+		Concurrency:  concurrency,
+		Rate:         rate,
+		MaxURLs:      maxURLs,
+		Headers:      headers,
+	}
+}
+
+func (uc *WarmupUseCase) Start(ctx context.Context, trigger string, extra []string) bool {
+	if !uc.begin(trigger) {
+		return false
+	}
+	go uc.run(ctx, extra)
+	return true
+}
+
+func (uc *WarmupUseCase) Run(ctx context.Context, trigger string, extra []string) (entity.WarmupProgress, bool) {
+	if !uc.begin(trigger) {
+		return uc.Progress(), false
+	}
+	uc.run(ctx, extra)
+	return uc.Progress(), true
+}
+
+func (uc *WarmupUseCase) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !uc.Start(ctx, "schedule", nil) {
+				uc.Logger.Warn(ctx, "Skipping scheduled cache warm-up: a run is still in progress")
+			}
+		}
+	}
+}
+
+func (uc *WarmupUseCase) Progress() entity.WarmupProgress {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	progress := uc.progress
+	progress.Sources = append([]entity.WarmupSourceResult(nil), uc.progress.Sources...)
+	return progress
+}
+
+// Ready fails until a warm-up run has finished, so a new instance only receives traffic
+// once its cache is warm.
+func (uc *WarmupUseCase) Ready() error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if !uc.completed {
+		return errWarmupPending
+	}
+	return nil
+}
+
+// begin claims the single run slot.
+func (uc *WarmupUseCase) begin(trigger string) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.progress.Running {
+		return false
+	}
+	uc.progress = entity.WarmupProgress{Running: true, Trigger: trigger, StartedAt: uc.TimeService.Now()}
+	return true
+}
+
+func (uc *WarmupUseCase) run(ctx context.Context, extra []string) {
+	urls := uc.collect(ctx, extra)
+	uc.Logger.Info(ctx, "Cache warm-up started", valueobject.LogField{Key: "trigger", Value: uc.Progress().Trigger}, valueobject.LogField{Key: "urls", Value: len(urls)})
+
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < uc.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range queue {
+				uc.warm(ctx, target)
+			}
+		}()
+	}
+
+	var tick <-chan time.Time
+	if uc.Rate > 0 {
+		// rates beyond one per nanosecond would round the interval down to zero, which
+		// NewTicker rejects
+		ticker := time.NewTicker(max(time.Duration(float64(time.Second)/uc.Rate), time.Nanosecond))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+dispatch:
+	for i, target := range urls {
+		// the first URL goes out immediately, later ones wait for the rate limiter
+		if tick != nil && i > 0 {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				break dispatch
+			}
+		}
+		select {
+		case queue <- target:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	uc.mu.Lock()
+	uc.progress.Running = false
+	uc.progress.FinishedAt = uc.TimeService.Now()
+	uc.completed = true
+	progress := uc.progress
+	uc.mu.Unlock()
+
+	uc.Logger.Info(ctx, "Cache warm-up finished",
+		valueobject.LogField{Key: "trigger", Value: progress.Trigger},
+		valueobject.LogField{Key: "done", Value: progress.Done},
+		valueobject.LogField{Key: "total", Value: progress.Total},
+		valueobject.LogField{Key: "already_cached", Value: progress.AlreadyCached},
+		valueobject.LogField{Key: "stored", Value: progress.Stored},
+		valueobject.LogField{Key: "uncacheable", Value: progress.Uncacheable},
+		valueobject.LogField{Key: "failed", Value: progress.Failed},
+		valueobject.LogField{Key: "duration", Value: progress.FinishedAt.Sub(progress.StartedAt).String()},
+	)
+}
+
+// collect gathers the seed URLs of every source, dropping duplicates and keeping the
+// order in which sources produced them.
+func (uc *WarmupUseCase) collect(ctx context.Context, extra []string) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	add := func(candidates []string) int {
+		added := 0
+		for _, candidate := range candidates {
+			if uc.MaxURLs > 0 && len(urls) >= uc.MaxURLs {
+				break
+			}
+			if candidate == "" || seen[candidate] {
+				continue
+			}
+			seen[candidate] = true
+			urls = append(urls, candidate)
+			added++
+		}
+		return added
+	}
+
+	results := make([]entity.WarmupSourceResult, 0, len(uc.Sources)+1)
+	if len(extra) > 0 {
+		results = append(results, entity.WarmupSourceResult{Name: "request", URLs: add(extra)})
+	}
+	for _, source := range uc.Sources {
+		result := entity.WarmupSourceResult{Name: source.Name()}
+		candidates, err := source.URLs(ctx)
+		if err != nil {
+			result.Error = err.Error()
+			uc.Logger.Warn(ctx, "Cache warm-up source failed", valueobject.LogField{Key: "source", Value: source.Name()}, valueobject.LogField{Key: "error", Value: err.Error()})
+		}
+		result.URLs = add(candidates)
+		results = append(results, result)
+	}
+
+	uc.mu.Lock()
+	uc.progress.Sources = results
+	uc.progress.Total = len(urls)
+	uc.mu.Unlock()
+	return urls
+}
+
+func (uc *WarmupUseCase) warm(ctx context.Context, target string) {
+	u, err := url.Parse(target)
+	if err == nil {
+		headers := uc.Headers.Clone()
+		if headers == nil {
+			headers = http.Header{}
+		}
+		headers.Set("User-Agent", warmupUserAgent)
+		var resp entity.ResponseModel
+		resp, err = uc.ProxyUseCase.ServeProxyRequest(ctx, entity.RequestModel{
+			Method:  http.MethodGet,
+			URL:     &url.URL{Path: u.Path, RawQuery: u.RawQuery},
+			Headers: headers,
+			Warmup:  true,
+		})
+		if err == nil && resp.Status >= http.StatusInternalServerError {
+			err = errors.New(http.StatusText(resp.Status))
+		}
+		if err == nil {
+			uc.record(resp)
+			return
+		}
+	}
+	uc.Logger.Debug(ctx, "Cache warm-up fetch failed", valueobject.LogField{Key: "url", Value: target}, valueobject.LogField{Key: "error", Value: err.Error()})
+	uc.mu.Lock()
+	uc.progress.Done++
+	uc.progress.Failed++
+	uc.mu.Unlock()
+}
+
+func (uc *WarmupUseCase) record(resp entity.ResponseModel) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.progress.Done++
+	switch {
+	case resp.CacheOutcome == valueobject.CacheOutcomeHit, resp.CacheOutcome == valueobject.CacheOutcomeStale, resp.CacheOutcome == valueobject.CacheOutcomeRevalidated:
+		uc.progress.AlreadyCached++
+	case resp.Cacheable:
+		uc.progress.Stored++
+	default:
+		uc.progress.Uncacheable++
+	}
+}